@@ -12,3 +12,26 @@ func Done(ctx context.Context) bool {
 		return false
 	}
 }
+
+// Send sends v to c, gives up if context is canceled.
+// Returns false if context is canceled.
+func Send[T any](ctx context.Context, c chan<- T, v T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case c <- v:
+		return true
+	}
+}
+
+// Recv receives a value from c, gives up if context is canceled.
+// Returns false if context is canceled or c is closed.
+func Recv[T any](ctx context.Context, c <-chan T) (T, bool) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	case v, ok := <-c:
+		return v, ok
+	}
+}
@@ -1,9 +1,9 @@
 package async
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"sync"
 
 	"github.com/berquerant/cache"
@@ -12,30 +12,90 @@ import (
 type ReadSeeker interface {
 	// Do call f in critical section.
 	Do(f func(io.ReadSeeker) error) error
+	// ReadAt reads len(p) bytes from offset off, safe for concurrent use.
+	// Returns an error if fewer bytes are read, like io.ReaderAt.
+	io.ReaderAt
 }
 
 type readSeeker struct {
 	sync.Mutex
 	io.ReadSeeker
+	at io.ReaderAt // nil if ReadSeeker is not io.ReaderAt
 }
 
 func (r *readSeeker) Do(f func(io.ReadSeeker) error) error {
 	r.Lock()
 	defer r.Unlock()
-	return f(r)
+	return f(r.ReadSeeker)
 }
 
+// ReadAt reads without lock if the underlying data is io.ReaderAt,
+// otherwise seeks and reads in critical section.
+func (r *readSeeker) ReadAt(p []byte, off int64) (int, error) {
+	if r.at != nil {
+		return r.at.ReadAt(p, off)
+	}
+	var n int
+	err := r.Do(func(data io.ReadSeeker) error {
+		if _, err := data.Seek(off, io.SeekStart); err != nil {
+			return err
+		}
+		var err error
+		n, err = io.ReadFull(data, p)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+		return err
+	})
+	return n, err
+}
+
+// NewReadSeeker returns a ReadSeeker of r.
+// Concurrent ReadAt does not block if r is io.ReaderAt, e.g. *os.File.
 func NewReadSeeker(r io.ReadSeeker) ReadSeeker {
-	return &readSeeker{
+	x := &readSeeker{
 		ReadSeeker: r,
 	}
+	if at, ok := r.(io.ReaderAt); ok {
+		x.at = at
+	}
+	return x
 }
 
 type CachedReader interface {
 	Read(offset int64, size int) ([]byte, error)
 }
 
+// NewRangeReader returns a reader that reads the byte ranges of r without caching.
+func NewRangeReader(r ReadSeeker) CachedReader {
+	return &rangeReader{
+		r: r,
+	}
+}
+
+type rangeReader struct {
+	r ReadSeeker
+}
+
+func (r *rangeReader) Read(offset int64, size int) ([]byte, error) {
+	result := make([]byte, size)
+	n, err := r.r.ReadAt(result, offset)
+	if n == size {
+		// io.ReaderAt may return io.EOF with the full range at the end of the data
+		return result, nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, fmt.Errorf("read offset %d size %d: got %d bytes: %w", offset, size, n, err)
+}
+
 func NewCachedReader(size int, r ReadSeeker) (CachedReader, error) {
+	return NewLRUReader(size, NewRangeReader(r))
+}
+
+// NewLRUReader returns a reader that caches the ranges read from r.
+func NewLRUReader(size int, r CachedReader) (CachedReader, error) {
 	c := &cachedReader{
 		r:    r,
 		size: size,
@@ -53,7 +113,7 @@ type cachedReaderKey struct {
 
 type cachedReader struct {
 	db   cache.Cache[cachedReaderKey, []byte]
-	r    ReadSeeker
+	r    CachedReader
 	size int
 }
 
@@ -67,21 +127,7 @@ func (c *cachedReader) init() error {
 }
 
 func (c *cachedReader) source(key cachedReaderKey) ([]byte, error) {
-	var result []byte
-	if err := c.r.Do(func(data io.ReadSeeker) error {
-		if _, err := data.Seek(key.offset, os.SEEK_SET); err != nil {
-			return err
-		}
-
-		result = make([]byte, key.size)
-		if _, err := data.Read(result); err != nil {
-			return err
-		}
-		return nil
-	}); err != nil {
-		return nil, err
-	}
-	return result, nil
+	return c.r.Read(key.offset, key.size)
 }
 
 func (c *cachedReader) Read(offset int64, size int) ([]byte, error) {
@@ -0,0 +1,86 @@
+package async_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/berquerant/joiny/async"
+	"github.com/stretchr/testify/assert"
+)
+
+// shortReadSeeker hides io.ReaderAt and reads at most 1 byte at once.
+type shortReadSeeker struct {
+	r *strings.Reader
+}
+
+func (s *shortReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return s.r.Seek(offset, whence)
+}
+
+func (s *shortReadSeeker) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return s.r.Read(p)
+}
+
+func TestRangeReader(t *testing.T) {
+	const content = "k1 v1\nk2 v2\nk3"
+
+	for _, tc := range []struct {
+		title string
+		data  io.ReadSeeker
+	}{
+		{
+			title: "reader at",
+			data:  strings.NewReader(content),
+		},
+		{
+			title: "short read fallback",
+			data:  &shortReadSeeker{r: strings.NewReader(content)},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			r := async.NewRangeReader(async.NewReadSeeker(tc.data))
+
+			t.Run("ranges", func(t *testing.T) {
+				for _, x := range []struct {
+					offset int64
+					size   int
+					want   string
+				}{
+					{offset: 0, size: 6, want: "k1 v1\n"},
+					{offset: 6, size: 6, want: "k2 v2\n"},
+					{offset: 12, size: 2, want: "k3"},
+				} {
+					got, err := r.Read(x.offset, x.size)
+					assert.Nil(t, err)
+					assert.Equal(t, x.want, string(got))
+				}
+			})
+
+			t.Run("beyond the end", func(t *testing.T) {
+				_, err := r.Read(12, 3)
+				assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+			})
+
+			t.Run("concurrent", func(t *testing.T) {
+				var wg sync.WaitGroup
+				for i := 0; i < 8; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						offset := int64(i%2) * 6
+						got, err := r.Read(offset, 5)
+						assert.Nil(t, err)
+						assert.Equal(t, fmt.Sprintf("k%d v%d", i%2+1, i%2+1), string(got))
+					}(i)
+				}
+				wg.Wait()
+			})
+		})
+	}
+}
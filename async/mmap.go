@@ -0,0 +1,72 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ErrMmapUnsupported = errors.New("MmapUnsupported")
+
+// MmapReader reads a memory-mapped file.
+// Read returns the slices of the mapping without copying, they must not be modified
+// and are valid until Close.
+type MmapReader struct {
+	data []byte
+}
+
+// NewMmapReader maps the whole file f into memory.
+// Returns ErrMmapUnsupported if f cannot be mapped, e.g. empty files, pipes, unsupported platforms.
+func NewMmapReader(f *os.File) (*MmapReader, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("Mmap: %w", err)
+	}
+	if !info.Mode().IsRegular() || info.Size() == 0 {
+		return nil, fmt.Errorf("Mmap: %w: %s is not a non-empty regular file", ErrMmapUnsupported, f.Name())
+	}
+	if int64(int(info.Size())) != info.Size() {
+		return nil, fmt.Errorf("Mmap: %w: %s is too large", ErrMmapUnsupported, f.Name())
+	}
+	data, err := mmap(f, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("Mmap: %s: %w", f.Name(), err)
+	}
+	return &MmapReader{
+		data: data,
+	}, nil
+}
+
+func (m *MmapReader) Read(offset int64, size int) ([]byte, error) {
+	if offset < 0 || size < 0 || offset+int64(size) > int64(len(m.data)) {
+		return nil, fmt.Errorf("read offset %d size %d: mapped %d bytes: %w", offset, size, len(m.data), io.ErrUnexpectedEOF)
+	}
+	return m.data[offset : offset+int64(size)], nil
+}
+
+func (m *MmapReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("read offset %d: %w", off, os.ErrInvalid)
+	}
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Len returns the size of the mapping.
+func (m *MmapReader) Len() int { return len(m.data) }
+
+func (m *MmapReader) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	return munmap(data)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package async
+
+import "os"
+
+func mmap(_ *os.File, _ int) ([]byte, error) { return nil, ErrMmapUnsupported }
+
+func munmap(_ []byte) error { return nil }
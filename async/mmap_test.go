@@ -0,0 +1,128 @@
+package async_test
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/async"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMmapReader(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("read", func(t *testing.T) {
+		f := newFile(t, filepath.Join(dir, "data"), "k1 v1\nk2 v2\nk3")
+		m, err := async.NewMmapReader(f)
+		if err != nil {
+			t.Skipf("mmap %v", err)
+		}
+		defer m.Close()
+		assert.Equal(t, 14, m.Len())
+
+		got, err := m.Read(6, 6)
+		assert.Nil(t, err)
+		assert.Equal(t, "k2 v2\n", string(got))
+		_, err = m.Read(12, 3)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+		p := make([]byte, 4)
+		n, err := m.ReadAt(p, 12)
+		assert.Equal(t, 2, n)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, "k3", string(p[:n]))
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		f := newFile(t, filepath.Join(dir, "empty"), "")
+		_, err := async.NewMmapReader(f)
+		assert.ErrorIs(t, err, async.ErrMmapUnsupported)
+	})
+}
+
+func newFile(tb testing.TB, name, content string) *os.File {
+	tb.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { f.Close() })
+	return f
+}
+
+type benchRange struct {
+	offset int64
+	size   int
+}
+
+// newBenchFile writes n lines and returns the ranges of them in random order.
+func newBenchFile(b *testing.B, n int) (*os.File, []benchRange) {
+	b.Helper()
+	var (
+		sb     strings.Builder
+		ranges = make([]benchRange, n)
+		offset int64
+	)
+	for i := range ranges {
+		line := fmt.Sprintf("%d,account%d,department%d,%s\n", i, i, i%100, strings.Repeat("x", 40))
+		sb.WriteString(line)
+		ranges[i] = benchRange{
+			offset: offset,
+			size:   len(line),
+		}
+		offset += int64(len(line))
+	}
+	rand.New(rand.NewSource(1)).Shuffle(n, func(i, j int) { ranges[i], ranges[j] = ranges[j], ranges[i] })
+	return newFile(b, filepath.Join(b.TempDir(), "bench"), sb.String()), ranges
+}
+
+func benchmarkReader(b *testing.B, r async.CachedReader, ranges []benchRange) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			x := ranges[i%len(ranges)]
+			if _, err := r.Read(x.offset, x.size); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkReader(b *testing.B) {
+	const lines = 100000
+
+	b.Run("cached", func(b *testing.B) {
+		f, ranges := newBenchFile(b, lines)
+		r, err := async.NewCachedReader(1024, async.NewReadSeeker(f))
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkReader(b, r, ranges)
+	})
+
+	b.Run("range", func(b *testing.B) {
+		f, ranges := newBenchFile(b, lines)
+		benchmarkReader(b, async.NewRangeReader(async.NewReadSeeker(f)), ranges)
+	})
+
+	b.Run("mmap", func(b *testing.B) {
+		f, ranges := newBenchFile(b, lines)
+		m, err := async.NewMmapReader(f)
+		if err != nil {
+			b.Skipf("mmap %v", err)
+		}
+		defer m.Close()
+		benchmarkReader(b, m, ranges)
+	})
+}
@@ -0,0 +1,88 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// Map applies f to the values of in by n goroutines.
+// If ordered, the results are sent in the order of in, otherwise as soon as they are ready.
+// The returned channel is closed when in is closed and all results are sent, or when ctx is canceled.
+func Map[T, U any](ctx context.Context, in <-chan T, n int, ordered bool, f func(T) U) <-chan U {
+	n = max(n, 1)
+	if ordered {
+		return mapOrdered(ctx, in, n, f)
+	}
+
+	var (
+		out = make(chan U, n)
+		wg  sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, ok := Recv(ctx, in)
+				if !ok || !Send(ctx, out, f(v)) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+type mapJob[T, U any] struct {
+	value  T
+	result chan U
+}
+
+// mapOrdered sends the jobs to the workers and their result channels to the collector in the same order,
+// so the collector waits the results in the order of in.
+// At most n jobs are ahead of the collector.
+func mapOrdered[T, U any](ctx context.Context, in <-chan T, n int, f func(T) U) <-chan U {
+	var (
+		out     = make(chan U, n)
+		jobs    = make(chan *mapJob[T, U], n)
+		pending = make(chan chan U, n)
+	)
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for {
+			v, ok := Recv(ctx, in)
+			if !ok {
+				return
+			}
+			job := &mapJob[T, U]{
+				value:  v,
+				result: make(chan U, 1),
+			}
+			if !Send(ctx, pending, job.result) || !Send(ctx, jobs, job) {
+				return
+			}
+		}
+	}()
+	for i := 0; i < n; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- f(job.value) // buffered
+			}
+		}()
+	}
+	go func() {
+		defer close(out)
+		for result := range pending {
+			v, ok := Recv(ctx, result)
+			if !ok || !Send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,79 @@
+package async_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/berquerant/joiny/async"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	const size = 100
+	values := func() <-chan int {
+		c := make(chan int)
+		go func() {
+			defer close(c)
+			for i := 0; i < size; i++ {
+				c <- i
+			}
+		}()
+		return c
+	}
+	// the former values take longer
+	square := func(v int) int {
+		time.Sleep(time.Duration(size-v) * 10 * time.Microsecond)
+		return v * v
+	}
+	want := make([]int, size)
+	for i := range want {
+		want[i] = i * i
+	}
+
+	for _, tc := range []struct {
+		title   string
+		n       int
+		ordered bool
+	}{
+		{
+			title:   "ordered",
+			n:       4,
+			ordered: true,
+		},
+		{
+			title: "unordered",
+			n:     4,
+		},
+		{
+			title:   "single worker",
+			n:       0,
+			ordered: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			var got []int
+			for v := range async.Map(context.TODO(), values(), tc.n, tc.ordered, square) {
+				got = append(got, v)
+			}
+			if !tc.ordered {
+				sort.Ints(got)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("cancel", func(t *testing.T) {
+		for _, ordered := range []bool{true, false} {
+			ctx, cancel := context.WithCancel(context.TODO())
+			in := make(chan int) // never closed
+			out := async.Map(ctx, in, 2, ordered, square)
+			in <- 1
+			assert.Equal(t, 1, <-out)
+			cancel()
+			for range out {
+			}
+		}
+	})
+}
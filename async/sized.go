@@ -0,0 +1,112 @@
+package async
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// CacheStats is the statistics of a read cache.
+type CacheStats struct {
+	Hits    int64
+	Misses  int64
+	Bytes   int
+	Entries int
+}
+
+// StatsReader is a CachedReader that counts the cache hits.
+type StatsReader interface {
+	CachedReader
+	Stats() CacheStats
+}
+
+// NewSizedLRUReader returns a reader that caches the ranges read from r up to limit bytes in total.
+// The ranges larger than limit are not cached.
+// Safe for concurrent use, so the indexes of a source can share it.
+func NewSizedLRUReader(limit int, r CachedReader) StatsReader {
+	return &sizedLRUReader{
+		r:     r,
+		limit: limit,
+		list:  list.New(),
+		items: make(map[cachedReaderKey]*list.Element),
+	}
+}
+
+type sizedLRUEntry struct {
+	key  cachedReaderKey
+	data []byte
+}
+
+type sizedLRUReader struct {
+	r      CachedReader
+	limit  int
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	mux   sync.Mutex
+	bytes int
+	list  *list.List // front is the most recently used
+	items map[cachedReaderKey]*list.Element
+}
+
+func (c *sizedLRUReader) Read(offset int64, size int) ([]byte, error) {
+	key := cachedReaderKey{
+		offset: offset,
+		size:   size,
+	}
+	if b, ok := c.get(key); ok {
+		c.hits.Add(1)
+		return b, nil
+	}
+	c.misses.Add(1)
+	b, err := c.r.Read(offset, size)
+	if err != nil {
+		return nil, err
+	}
+	c.add(key, b)
+	return b, nil
+}
+
+func (c *sizedLRUReader) get(key cachedReaderKey) ([]byte, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.list.MoveToFront(e)
+	return e.Value.(*sizedLRUEntry).data, true
+}
+
+func (c *sizedLRUReader) add(key cachedReaderKey, data []byte) {
+	if len(data) > c.limit {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.items[key]; ok {
+		return // added by another reader
+	}
+	c.items[key] = c.list.PushFront(&sizedLRUEntry{
+		key:  key,
+		data: data,
+	})
+	c.bytes += len(data)
+	for c.bytes > c.limit {
+		e := c.list.Back()
+		x := c.list.Remove(e).(*sizedLRUEntry)
+		delete(c.items, x.key)
+		c.bytes -= len(x.data)
+	}
+}
+
+func (c *sizedLRUReader) Stats() CacheStats {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Bytes:   c.bytes,
+		Entries: c.list.Len(),
+	}
+}
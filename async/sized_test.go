@@ -0,0 +1,39 @@
+package async_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/async"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizedLRUReader(t *testing.T) {
+	const content = "k1 v1\nk2 v2\nk3 v3\n"
+	read := func(t *testing.T, r async.CachedReader, offset int64, size int) {
+		t.Helper()
+		got, err := r.Read(offset, size)
+		assert.Nil(t, err)
+		assert.Equal(t, content[offset:offset+int64(size)], string(got))
+	}
+
+	t.Run("evict by bytes", func(t *testing.T) {
+		r := async.NewSizedLRUReader(12, async.NewRangeReader(async.NewReadSeeker(strings.NewReader(content))))
+		read(t, r, 0, 6)
+		read(t, r, 6, 6)
+		read(t, r, 0, 6) // hit, line 2 is the least recently used
+		assert.Equal(t, async.CacheStats{Hits: 1, Misses: 2, Bytes: 12, Entries: 2}, r.Stats())
+
+		read(t, r, 12, 6) // evict line 2
+		read(t, r, 0, 6)
+		read(t, r, 6, 6)
+		assert.Equal(t, async.CacheStats{Hits: 2, Misses: 4, Bytes: 12, Entries: 2}, r.Stats())
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		r := async.NewSizedLRUReader(4, async.NewRangeReader(async.NewReadSeeker(strings.NewReader(content))))
+		read(t, r, 0, 6)
+		read(t, r, 0, 6)
+		assert.Equal(t, async.CacheStats{Hits: 0, Misses: 2}, r.Stats())
+	})
+}
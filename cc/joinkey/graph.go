@@ -0,0 +1,215 @@
+package joinkey
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Expr returns the location in the key syntax, like "1.3".
+func (l *Location) Expr() string { return fmt.Sprintf("%d.%d", l.Src, l.Col) }
+
+// Expr returns the relation in the key syntax, like "1.3=2.2".
+func (r *Relation) Expr() string { return r.Left.Expr() + "=" + r.Right.Expr() }
+
+// Expr returns the key in the key syntax, like "1.3=2.2,2.3=3.1".
+func (k *JoinKey) Expr() string {
+	ss := make([]string, len(k.RelationList))
+	for i, r := range k.RelationList {
+		ss[i] = r.Expr()
+	}
+	return strings.Join(ss, ",")
+}
+
+var (
+	ErrEmptyKey            = errors.New("EmptyKey")
+	ErrSourceOutOfRange    = errors.New("SourceOutOfRange")
+	ErrUnreferencedSource  = errors.New("UnreferencedSource")
+	ErrDisconnectedSources = errors.New("DisconnectedSources")
+)
+
+// Validate checks that the key joins all n sources as a connected graph.
+// Sources are the vertices and relations are the edges.
+// The relations may be in any order, see Connected.
+func (k *JoinKey) Validate(n int) error {
+	if len(k.RelationList) == 0 {
+		return fmt.Errorf("%w: specify relations like 1.1=2.1", ErrEmptyKey)
+	}
+
+	var errList []error
+	for i, r := range k.RelationList {
+		for _, loc := range []*Location{r.Left, r.Right} {
+			if loc.Src < 1 || loc.Src > n {
+				errList = append(errList, fmt.Errorf("%w: relation %d (%s) refers source %d but there are %d sources, source numbers are from 1 to %d",
+					ErrSourceOutOfRange, i+1, r.Expr(), loc.Src, n, n,
+				))
+			}
+		}
+	}
+	if len(errList) > 0 {
+		return errors.Join(errList...)
+	}
+
+	referenced := make(map[int]bool)
+	for _, r := range k.RelationList {
+		referenced[r.Left.Src] = true
+		referenced[r.Right.Src] = true
+	}
+	for src := 1; src <= n; src++ {
+		if !referenced[src] {
+			errList = append(errList, fmt.Errorf("%w: source %d is not referenced by the key, add a relation like %d.1=%d.1 or remove the source",
+				ErrUnreferencedSource, src, nearestSource(referenced, src), src,
+			))
+		}
+	}
+
+	components := k.components()
+	if len(components) > 1 {
+		ss := make([]string, len(components))
+		for i, c := range components {
+			ss[i] = formatSources(c)
+		}
+		errList = append(errList, fmt.Errorf("%w: %s are not connected, add relations between them like %d.1=%d.1",
+			ErrDisconnectedSources, strings.Join(ss, " and "), components[0][0], components[1][0],
+		))
+	}
+	return errors.Join(errList...)
+}
+
+// Connected returns the key whose relations are reordered so that
+// each relation shares a source with the preceding relations, keeping the order as possible.
+func (k *JoinKey) Connected() *JoinKey {
+	var (
+		rest   = append([]*Relation{}, k.RelationList...)
+		r      = make([]*Relation, 0, len(rest))
+		joined = make(map[int]bool)
+	)
+	for len(rest) > 0 {
+		next := 0
+		for i, x := range rest {
+			if joined[x.Left.Src] || joined[x.Right.Src] {
+				next = i
+				break
+			}
+		}
+		x := rest[next]
+		joined[x.Left.Src] = true
+		joined[x.Right.Src] = true
+		r = append(r, x)
+		rest = append(rest[:next], rest[next+1:]...)
+	}
+	return NewJoinKey(r)
+}
+
+// Redundant returns the relations implied by the preceding relations,
+// e.g. the 3rd relation of 1.1=2.1,2.1=3.1,3.1=1.1 is redundant because the values of the columns are already equal.
+// The single relation is not redundant even if it is the identity like 1.1=1.1.
+func (k *JoinKey) Redundant() []*Relation {
+	if len(k.RelationList) < 2 {
+		return nil
+	}
+	var (
+		r  []*Relation
+		uf = newUnionFind[Location]()
+	)
+	for _, x := range k.RelationList {
+		if !uf.union(*x.Left, *x.Right) {
+			r = append(r, x)
+		}
+	}
+	return r
+}
+
+// components returns the connected components of the sources, each component is sorted.
+func (k *JoinKey) components() [][]int {
+	uf := newUnionFind[int]()
+	for _, x := range k.RelationList {
+		uf.union(x.Left.Src, x.Right.Src)
+	}
+	m := make(map[int][]int)
+	for _, x := range k.RelationList {
+		for _, src := range []int{x.Left.Src, x.Right.Src} {
+			root := uf.find(src)
+			if !containsInt(m[root], src) {
+				m[root] = append(m[root], src)
+			}
+		}
+	}
+	r := make([][]int, 0, len(m))
+	for _, c := range m {
+		sort.Ints(c)
+		r = append(r, c)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i][0] < r[j][0] })
+	return r
+}
+
+func containsInt(xs []int, v int) bool {
+	for _, x := range xs {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestSource returns the referenced source closest to src.
+func nearestSource(referenced map[int]bool, src int) int {
+	r := -1
+	for x := range referenced {
+		if r < 0 {
+			r = x
+			continue
+		}
+		if d, e := abs(x-src), abs(r-src); d < e || (d == e && x < r) {
+			r = x
+		}
+	}
+	return r
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func formatSources(srcs []int) string {
+	ss := make([]string, len(srcs))
+	for i, x := range srcs {
+		ss[i] = fmt.Sprint(x)
+	}
+	return "sources {" + strings.Join(ss, ",") + "}"
+}
+
+type unionFind[T comparable] struct {
+	parent map[T]T
+}
+
+func newUnionFind[T comparable]() *unionFind[T] {
+	return &unionFind[T]{
+		parent: make(map[T]T),
+	}
+}
+
+func (u *unionFind[T]) find(x T) T {
+	p, ok := u.parent[x]
+	if !ok || p == x {
+		return x
+	}
+	root := u.find(p)
+	u.parent[x] = root
+	return root
+}
+
+// union merges the sets of x and y, returns false if they are already in the same set.
+func (u *unionFind[T]) union(x, y T) bool {
+	rx, ry := u.find(x), u.find(y)
+	if rx == ry {
+		return false
+	}
+	u.parent[rx] = ry
+	return true
+}
@@ -0,0 +1,126 @@
+package joinkey_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseKey(t *testing.T, input string) *joinkey.JoinKey {
+	t.Helper()
+	lex := joinkey.NewLexer(bytes.NewBufferString(input))
+	_ = joinkey.Parse(lex)
+	if err := lex.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lex.JoinKey
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		input string
+		n     int
+		err   error
+	}{
+		{
+			title: "identity",
+			input: "1.1=1.1",
+			n:     1,
+		},
+		{
+			title: "chain",
+			input: "1.3=2.2,2.3=3.1",
+			n:     3,
+		},
+		{
+			title: "internal join",
+			input: "1.1=2.1,2.1=3.1,2.2=1.1",
+			n:     3,
+		},
+		{
+			title: "source out of range",
+			input: "1.1=4.1",
+			n:     3,
+			err:   joinkey.ErrSourceOutOfRange,
+		},
+		{
+			title: "unreferenced",
+			input: "1.1=2.1",
+			n:     3,
+			err:   joinkey.ErrUnreferencedSource,
+		},
+		{
+			title: "disconnected",
+			input: "1.1=2.1,3.1=4.1",
+			n:     4,
+			err:   joinkey.ErrDisconnectedSources,
+		},
+		{
+			title: "unordered",
+			input: "1.1=2.1,3.1=4.1,2.1=3.1",
+			n:     4,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			err := parseKey(t, tc.input).Validate(tc.n)
+			if tc.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	t.Run("source zero", func(t *testing.T) {
+		// the parser rejects 0.1=1.1
+		key := joinkey.NewJoinKey([]*joinkey.Relation{
+			joinkey.NewRelation(joinkey.NewLocation(0, 1), joinkey.NewLocation(1, 1)),
+		})
+		assert.ErrorIs(t, key.Validate(1), joinkey.ErrSourceOutOfRange)
+	})
+}
+
+func TestConnected(t *testing.T) {
+	assert.Equal(t, "1.1=2.1,2.1=3.1,3.1=4.1", parseKey(t, "1.1=2.1,3.1=4.1,2.1=3.1").Connected().Expr())
+	assert.Equal(t, "1.3=2.2,2.3=3.1", parseKey(t, "1.3=2.2,2.3=3.1").Connected().Expr())
+}
+
+func TestRedundant(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		input string
+		want  []string
+	}{
+		{
+			title: "identity",
+			input: "1.1=1.1",
+		},
+		{
+			title: "duplicate",
+			input: "1.1=2.1,2.1=1.1",
+			want:  []string{"2.1=1.1"},
+		},
+		{
+			title: "transitive",
+			input: "1.1=2.1,2.1=3.1,3.1=1.1",
+			want:  []string{"3.1=1.1"},
+		},
+		{
+			title: "different columns",
+			input: "1.1=2.1,2.2=1.1",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var got []string
+			for _, r := range parseKey(t, tc.input).Redundant() {
+				got = append(got, r.Expr())
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
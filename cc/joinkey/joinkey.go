@@ -463,8 +463,8 @@ yydefault:
 //line cc/joinkey/joinkey.y:48
 		{
 			lex := yylex.(*Lexer)
-			left := int(lex.ParseUint(yyDollar[1].token.Value()))
-			right := int(lex.ParseUint(yyDollar[3].token.Value()))
+			left := lex.ParseIndex(yyDollar[1].token, "source")
+			right := lex.ParseIndex(yyDollar[3].token, "column")
 			yyVAL.location = NewLocation(left, right)
 		}
 	}
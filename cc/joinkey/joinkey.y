@@ -47,7 +47,7 @@ relation:
 location:
   UINT DOT UINT {
     lex := yylex.(*Lexer)
-    left := int(lex.ParseUint($1.Value()))
-    right := int(lex.ParseUint($3.Value()))
+    left := lex.ParseIndex($1, "source")
+    right := lex.ParseIndex($3, "column")
     $$ = NewLocation(left, right)
   }
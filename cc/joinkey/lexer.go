@@ -1,20 +1,22 @@
 package joinkey
 
 import (
+	"bytes"
 	"fmt"
 	"io"
-	"log/slog"
 	"strconv"
+	"strings"
 	"unicode"
 
+	"github.com/berquerant/joiny/cc/parseerr"
 	"github.com/berquerant/joiny/logx"
 	"github.com/berquerant/ybase"
 )
 
 func Parse(lexer *Lexer) int {
-	logx.G().Debug("Begin parse joinkey")
+	logx.C(logx.Parser).Debug("Begin parse joinkey")
 	defer func() {
-		logx.G().Debug("End parse joinkey", logx.Any("key", lexer.JoinKey))
+		logx.C(logx.Parser).Debug("End parse joinkey", logx.Any("key", lexer.JoinKey))
 	}()
 	return yyParse(lexer)
 }
@@ -42,43 +44,120 @@ func ScanToken(r ybase.Reader) int {
 
 type Lexer struct {
 	ybase.Lexer
-	JoinKey *JoinKey
+	JoinKey  *JoinKey
+	rest     io.Reader     // the input not read yet
+	input    *bytes.Buffer // the input read
+	offset   int           // of the last token
+	err      *parseerr.Error
+	expected []string
 }
 
 func NewLexer(r io.Reader) *Lexer {
 	yyErrorVerbose = true
 	debug := func(msg string, v ...any) {
-		logx.G().Debug(fmt.Sprintf(msg, v...))
+		logx.C(logx.Parser).Debug(fmt.Sprintf(msg, v...))
 	}
+	var input bytes.Buffer
+	rest := io.TeeReader(r, &input)
 	return &Lexer{
 		Lexer: ybase.NewLexer(ybase.NewScanner(
-			ybase.NewReader(r, debug),
+			ybase.NewReader(rest, debug),
 			ScanToken,
 		)),
+		rest:  rest,
+		input: &input,
 	}
 }
 
-func (l *Lexer) ParseUint(value string) uint {
-	ui, err := strconv.ParseUint(value, 10, 32)
+var tokens = []parseerr.Token{
+	{Name: "UINT", Desc: "number"},
+	{Name: "EQUAL", Desc: "'='", Text: "="},
+	{Name: "DOT", Desc: "'.'", Text: "."},
+	{Name: "COMMA", Desc: "','", Text: ","},
+}
+
+// Error records the syntax error at the last token.
+func (l *Lexer) Error(msg string) {
+	expected, err := parseerr.Syntax(msg, tokens)
+	l.fail(l.offset, err, expected)
+}
+
+func (l *Lexer) fail(offset int, err error, expected []string) {
+	if l.err != nil {
+		return
+	}
+	l.err = &parseerr.Error{
+		Kind:   "key",
+		Offset: offset,
+		Err:    err,
+	}
+	l.expected = expected
+}
+
+// Err returns the first error with the position and the suggestions, as *parseerr.Error.
+func (l *Lexer) Err() error {
+	if l.err == nil {
+		return l.Lexer.Err()
+	}
+	if l.rest != nil {
+		_, _ = io.Copy(io.Discard, l.rest)
+		l.rest = nil
+		l.err.Input = l.input.String()
+		l.err.Suggestions = parseerr.Suggest(l.err.Input, l.err.Offset, l.expected, valid,
+			strings.NewReplacer(":", ".", ";", ",", "==", "=").Replace,
+			parseerr.OneBased,
+			parseerr.TrimTrailing(",="),
+		)
+	}
+	return l.err
+}
+
+func valid(input string) bool {
+	l := NewLexer(strings.NewReader(input))
+	_ = yyParse(l)
+	return l.err == nil && l.Lexer.Err() == nil
+}
+
+// ParseIndex parses the token as a source or a column number, which is one-based.
+func (l *Lexer) ParseIndex(tok ybase.Token, name string) int {
+	offset := tokenOffset(tok)
+	ui, err := strconv.ParseUint(tok.Value(), 10, 32)
 	if err != nil {
-		l.Errorf(err, "Cannot parse", slog.String("value", value))
+		l.fail(offset, fmt.Errorf("%w: %s %s", parseerr.ErrInvalidNumber, name, tok.Value()), nil)
+		return 0
+	}
+	if ui == 0 {
+		l.fail(offset, fmt.Errorf("%w: %s should be 1 or greater", parseerr.ErrInvalidNumber, name), nil)
 		return 0
 	}
-	return uint(ui)
+	return int(ui)
+}
+
+// tokenOffset returns the offset of the token without the leading spaces.
+func tokenOffset(tok ybase.Token) int {
+	return tok.End().Offset() - len(tok.Value())
 }
 
 func (l *Lexer) Lex(lval *yySymType) int {
-	return l.DoLex(func(tok ybase.Token) {
+	t := l.DoLex(func(tok ybase.Token) {
 		lval.token = tok
+		l.offset = tokenOffset(tok)
 	})
+	if t == ybase.EOF {
+		l.offset = l.Pos().Offset()
+		if c := l.Peek(); c != ybase.EOF {
+			l.fail(l.offset, fmt.Errorf("%w: %q", parseerr.ErrUnexpectedCharacter, c), nil)
+		}
+	}
+	return t
 }
 
+// Debug enables the debug output of the generated parser by the level
+// if the parser component of logx logs at the debug level.
 func (*Lexer) Debug(level int) {
-	switch {
-	case level > 0:
-		logx.G().SetLevel(logx.Ldebug)
+	if level > 0 && logx.C(logx.Parser).Enabled(logx.Ldebug) {
 		yyDebug = level
-	case level == 0:
-		logx.G().SetLevel(logx.Linfo)
+		return
 	}
+	yyDebug = 0
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/parseerr"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 )
@@ -49,3 +50,77 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseError(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		input       string
+		column      int
+		want        error
+		suggestions []string
+	}{
+		{
+			title:       "unexpected token",
+			input:       "1.3=2,2",
+			column:      6,
+			want:        parseerr.ErrSyntax,
+			suggestions: []string{"1.3=2.2"},
+		},
+		{
+			title:  "unexpected end",
+			input:  "1.2=2",
+			column: 6,
+			want:   parseerr.ErrSyntax,
+		},
+		{
+			title:       "unexpected character",
+			input:       "1:2 = 2:1",
+			column:      2,
+			want:        parseerr.ErrUnexpectedCharacter,
+			suggestions: []string{"1.2 = 2.1"},
+		},
+		{
+			title:       "trailing comma",
+			input:       "1.2=2.1,",
+			column:      9,
+			want:        parseerr.ErrSyntax,
+			suggestions: []string{"1.2=2.1"},
+		},
+		{
+			title:       "zero source",
+			input:       "1.2=0.1",
+			column:      5,
+			want:        parseerr.ErrInvalidNumber,
+			suggestions: []string{"1.2=1.1"},
+		},
+		{
+			title:       "zero column",
+			input:       "1.2=2.00",
+			column:      7,
+			want:        parseerr.ErrInvalidNumber,
+			suggestions: []string{"1.2=2.1"},
+		},
+		{
+			title:       "zero and one-based",
+			input:       "1.3=2.0",
+			column:      7,
+			want:        parseerr.ErrInvalidNumber,
+			suggestions: []string{"1.3=2.1"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			lex := joinkey.NewLexer(bytes.NewBufferString(tc.input))
+			_ = joinkey.Parse(lex)
+			err := lex.Err()
+			assert.ErrorIs(t, err, tc.want)
+			var parseErr *parseerr.Error
+			if !assert.ErrorAs(t, err, &parseErr) {
+				return
+			}
+			assert.Equal(t, tc.input, parseErr.Input)
+			assert.Equal(t, tc.column, parseErr.Column())
+			assert.Equal(t, tc.suggestions, parseErr.Suggestions)
+		})
+	}
+}
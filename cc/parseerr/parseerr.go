@@ -0,0 +1,179 @@
+// Package parseerr provides the errors of the parsers of the expressions with their positions.
+package parseerr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrSyntax              = errors.New("Syntax")
+	ErrUnexpectedCharacter = errors.New("UnexpectedCharacter")
+	ErrInvalidNumber       = errors.New("InvalidNumber")
+)
+
+// Error is an error at a position of an expression.
+type Error struct {
+	Kind        string // what the expression is, like "key"
+	Input       string
+	Offset      int // bytes of Input before the failure
+	Err         error
+	Suggestions []string
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Column returns the one-based column of the failure.
+func (e *Error) Column() int {
+	return utf8.RuneCountInString(e.Input[:e.offset()]) + 1
+}
+
+func (e *Error) offset() int { return max(0, min(e.Offset, len(e.Input))) }
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("invalid %s %q at column %d: %v", e.Kind, e.Input, e.Column(), e.Err)
+	if len(e.Suggestions) > 0 {
+		s += fmt.Sprintf(", did you mean %s?", strings.Join(e.Suggestions, " or "))
+	}
+	return s
+}
+
+// Detail returns the message with the expression and a caret at the failure, like
+//
+//	invalid key: Syntax: unexpected end of input, expected '.'
+//	  1.2=2
+//	       ^
+//	  did you mean 1.2=2.2?
+func (e *Error) Detail() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid %s: %v\n", e.Kind, e.Err)
+	fmt.Fprintf(&b, "  %s\n", e.Input)
+	fmt.Fprintf(&b, "  %s^", strings.Repeat(" ", e.Column()-1))
+	if len(e.Suggestions) > 0 {
+		fmt.Fprintf(&b, "\n  did you mean %s?", strings.Join(e.Suggestions, " or "))
+	}
+	return b.String()
+}
+
+// Token describes a token of a grammar.
+type Token struct {
+	Name string // name in the grammar, like "DOT"
+	Desc string // readable name, like "'.'"
+	Text string // text of the token, empty if not fixed
+}
+
+func findToken(tokens []Token, name string) (Token, bool) {
+	for _, t := range tokens {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Token{}, false
+}
+
+// Syntax converts a message of the parser generated by goyacc,
+// like "syntax error: unexpected $end, expecting DOT", into an error with the readable names of the tokens.
+// Returns the texts of the expected tokens and the error.
+func Syntax(msg string, tokens []Token) ([]string, error) {
+	msg = strings.TrimPrefix(msg, "syntax error")
+	msg = strings.TrimPrefix(msg, ": ")
+	if !strings.HasPrefix(msg, "unexpected ") {
+		if msg == "" {
+			return nil, ErrSyntax
+		}
+		return nil, fmt.Errorf("%w: %s", ErrSyntax, msg)
+	}
+
+	desc := func(name string) string {
+		if name == "$end" {
+			return "end of input"
+		}
+		if t, ok := findToken(tokens, name); ok {
+			return t.Desc
+		}
+		return name
+	}
+	unexpected, expecting, found := strings.Cut(strings.TrimPrefix(msg, "unexpected "), ", expecting ")
+	if !found {
+		return nil, fmt.Errorf("%w: unexpected %s", ErrSyntax, desc(unexpected))
+	}
+	var (
+		names    = strings.Split(expecting, " or ")
+		descs    = make([]string, len(names))
+		expected []string
+	)
+	for i, name := range names {
+		descs[i] = desc(name)
+		if t, ok := findToken(tokens, name); ok && t.Text != "" {
+			expected = append(expected, t.Text)
+		}
+	}
+	return expected, fmt.Errorf("%w: unexpected %s, expected %s", ErrSyntax, desc(unexpected), strings.Join(descs, " or "))
+}
+
+// maxSuggestions is the maximum number of the suggestions.
+const maxSuggestions = 3
+
+// Suggest returns the candidates accepted by valid.
+// The candidates are made by replacing the character at the offset with the expected text, inserting the expected text at the offset,
+// applying each of the rewrites and applying all of the rewrites.
+func Suggest(input string, offset int, expected []string, valid func(string) bool, rewrites ...func(string) string) []string {
+	offset = max(0, min(offset, len(input)))
+	var candidates []string
+	for _, x := range expected {
+		if offset < len(input) {
+			_, size := utf8.DecodeRuneInString(input[offset:])
+			candidates = append(candidates, input[:offset]+x+input[offset+size:])
+		}
+		candidates = append(candidates, input[:offset]+x+input[offset:])
+	}
+	all := input
+	for _, f := range rewrites {
+		candidates = append(candidates, f(input))
+		all = f(all)
+	}
+	candidates = append(candidates, all)
+
+	var (
+		r    []string
+		seen = map[string]bool{input: true}
+	)
+	for _, x := range candidates {
+		if seen[x] {
+			continue
+		}
+		seen[x] = true
+		if valid(x) {
+			r = append(r, x)
+		}
+		if len(r) == maxSuggestions {
+			break
+		}
+	}
+	return r
+}
+
+var numberRegexp = regexp.MustCompile(`\d+`)
+
+// OneBased replaces the numbers that are 0 with 1,
+// assuming that they are written in zero-based numbers by mistake.
+// The other numbers are kept because they are already one-based.
+func OneBased(input string) string {
+	return numberRegexp.ReplaceAllStringFunc(input, func(x string) string {
+		if n, err := strconv.ParseUint(x, 10, 32); err == nil && n == 0 {
+			return "1"
+		}
+		return x
+	})
+}
+
+// TrimTrailing removes the trailing spaces and separators.
+func TrimTrailing(seps string) func(string) string {
+	return func(input string) string {
+		return strings.TrimRight(input, " \t\n"+seps)
+	}
+}
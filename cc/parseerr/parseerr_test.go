@@ -0,0 +1,127 @@
+package parseerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/parseerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	err := &parseerr.Error{
+		Kind:        "key",
+		Input:       "1.3=2,2",
+		Offset:      5,
+		Err:         errors.New("Syntax: unexpected ','"),
+		Suggestions: []string{"1.3=2.2"},
+	}
+	assert.Equal(t, 6, err.Column())
+	assert.Equal(t, `invalid key "1.3=2,2" at column 6: Syntax: unexpected ',', did you mean 1.3=2.2?`, err.Error())
+	assert.Equal(t, `invalid key: Syntax: unexpected ','
+  1.3=2,2
+       ^
+  did you mean 1.3=2.2?`, err.Detail())
+}
+
+func TestSyntax(t *testing.T) {
+	tokens := []parseerr.Token{
+		{Name: "UINT", Desc: "number"},
+		{Name: "DOT", Desc: "'.'", Text: "."},
+		{Name: "MINUS", Desc: "'-'", Text: "-"},
+	}
+	for _, tc := range []struct {
+		title    string
+		msg      string
+		want     string
+		expected []string
+	}{
+		{
+			title: "no details",
+			msg:   "syntax error",
+			want:  "Syntax",
+		},
+		{
+			title: "unexpected",
+			msg:   "syntax error: unexpected MINUS",
+			want:  "Syntax: unexpected '-'",
+		},
+		{
+			title:    "expecting",
+			msg:      "syntax error: unexpected $end, expecting UINT or MINUS",
+			want:     "Syntax: unexpected end of input, expected number or '-'",
+			expected: []string{"-"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			expected, err := parseerr.Syntax(tc.msg, tokens)
+			assert.ErrorIs(t, err, parseerr.ErrSyntax)
+			assert.Equal(t, tc.want, err.Error())
+			assert.Equal(t, tc.expected, expected)
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	// accepts like "1.2,3.4"
+	valid := func(s string) bool {
+		for _, x := range strings.Split(s, ",") {
+			l, r, ok := strings.Cut(x, ".")
+			if !ok || l == "" || r == "" || l == "0" || r == "0" || strings.ContainsAny(l+r, ".,:") {
+				return false
+			}
+		}
+		return true
+	}
+	for _, tc := range []struct {
+		title    string
+		input    string
+		offset   int
+		expected []string
+		want     []string
+	}{
+		{
+			title:    "replace",
+			input:    "1,2",
+			offset:   1,
+			expected: []string{"."},
+			want:     []string{"1.2"},
+		},
+		{
+			title:    "insert",
+			input:    "12",
+			offset:   1,
+			expected: []string{"."},
+			want:     []string{"1.2"},
+		},
+		{
+			title:  "rewrite",
+			input:  "0:1,",
+			offset: 1,
+			want:   []string{"1.1"},
+		},
+		{
+			title: "none",
+			input: "1",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got := parseerr.Suggest(tc.input, tc.offset, tc.expected, valid,
+				strings.NewReplacer(":", ".").Replace,
+				parseerr.OneBased,
+				parseerr.TrimTrailing(","),
+			)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOneBased(t *testing.T) {
+	assert.Equal(t, "1.1=1.1", parseerr.OneBased("0.1=1.1"))
+	assert.Equal(t, "1.2=2.2", parseerr.OneBased("1.2=2.2"))
+	assert.Equal(t, "1.3=2.1", parseerr.OneBased("1.3=2.0"))
+	assert.Equal(t, "1.10=1.1", parseerr.OneBased("0.10=1.00"))
+}
@@ -0,0 +1,268 @@
+package sql
+
+import "fmt"
+
+type Node interface {
+	IsNode()
+}
+
+//go:generate go run github.com/berquerant/marker@v0.1.4 -method IsNode -type Statement,Source,Join,ColumnRef,AllColumns,Literal,Comparison,And,Or,Not,Order -output ast_marker_node_generated.go
+
+// Statement is a SELECT statement.
+type Statement struct {
+	// Columns is the select list, nil means `*`.
+	Columns []Column
+	From    *Source
+	Joins   []*Join
+	// Where is nil if no WHERE clause.
+	Where   Condition
+	OrderBy []*Order
+	// Limit is negative if no LIMIT clause.
+	Limit int
+}
+
+func NewStatement(columns []Column, from *Source, joins []*Join, where Condition, orderBy []*Order, limit int) *Statement {
+	return &Statement{
+		Columns: columns,
+		From:    from,
+		Joins:   joins,
+		Where:   where,
+		OrderBy: orderBy,
+		Limit:   limit,
+	}
+}
+
+// Source is a file in FROM or JOIN clause.
+type Source struct {
+	Path string
+	// Alias is empty if not specified.
+	Alias string
+}
+
+func (s *Source) String() string { return fmt.Sprintf("Source(%s, %s)", s.Path, s.Alias) }
+
+func NewSource(path, alias string) *Source {
+	return &Source{
+		Path:  path,
+		Alias: alias,
+	}
+}
+
+type JoinType int
+
+const (
+	InnerJoin JoinType = iota
+	LeftJoin
+	RightJoin
+	FullJoin
+	CrossJoin
+)
+
+func (t JoinType) String() string {
+	switch t {
+	case InnerJoin:
+		return "INNER"
+	case LeftJoin:
+		return "LEFT"
+	case RightJoin:
+		return "RIGHT"
+	case FullJoin:
+		return "FULL"
+	case CrossJoin:
+		return "CROSS"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Join is a JOIN clause.
+type Join struct {
+	Type   JoinType
+	Source *Source
+	// On is nil if no ON clause.
+	On Condition
+}
+
+func NewJoin(joinType JoinType, source *Source, on Condition) *Join {
+	return &Join{
+		Type:   joinType,
+		Source: source,
+		On:     on,
+	}
+}
+
+// Column is an element of the select list.
+type Column interface {
+	Node
+	IsColumn()
+}
+
+//go:generate go run github.com/berquerant/marker@v0.1.4 -method IsColumn -type ColumnRef,AllColumns -output ast_marker_column_generated.go
+
+// ColumnRef means the specified column of the aliased source, like `a.3`.
+type ColumnRef struct {
+	Alias string
+	Col   int
+}
+
+func (c *ColumnRef) String() string { return fmt.Sprintf("ColumnRef(%s, %d)", c.Alias, c.Col) }
+
+func NewColumnRef(alias string, col int) *ColumnRef {
+	return &ColumnRef{
+		Alias: alias,
+		Col:   col,
+	}
+}
+
+// AllColumns means all columns of the aliased source, like `a.*`.
+type AllColumns struct {
+	Alias string
+}
+
+func (c *AllColumns) String() string { return fmt.Sprintf("AllColumns(%s)", c.Alias) }
+
+func NewAllColumns(alias string) *AllColumns {
+	return &AllColumns{
+		Alias: alias,
+	}
+}
+
+// Operand is a side of the comparison.
+type Operand interface {
+	Node
+	IsOperand()
+}
+
+//go:generate go run github.com/berquerant/marker@v0.1.4 -method IsOperand -type ColumnRef,Literal -output ast_marker_operand_generated.go
+
+// Literal is a string or a number.
+type Literal struct {
+	Value string
+}
+
+func (l *Literal) String() string { return fmt.Sprintf("Literal(%s)", l.Value) }
+
+func NewLiteral(value string) *Literal {
+	return &Literal{
+		Value: value,
+	}
+}
+
+type Operator int
+
+const (
+	OpEqual Operator = iota
+	OpNotEqual
+	OpLess
+	OpLessEqual
+	OpGreater
+	OpGreaterEqual
+)
+
+func (o Operator) String() string {
+	switch o {
+	case OpEqual:
+		return "="
+	case OpNotEqual:
+		return "<>"
+	case OpLess:
+		return "<"
+	case OpLessEqual:
+		return "<="
+	case OpGreater:
+		return ">"
+	case OpGreaterEqual:
+		return ">="
+	default:
+		return "?"
+	}
+}
+
+// Flip returns the operator which swaps the sides, e.g. `<` into `>`.
+func (o Operator) Flip() Operator {
+	switch o {
+	case OpLess:
+		return OpGreater
+	case OpLessEqual:
+		return OpGreaterEqual
+	case OpGreater:
+		return OpLess
+	case OpGreaterEqual:
+		return OpLessEqual
+	default:
+		return o
+	}
+}
+
+// Condition is an expression in ON or WHERE clause.
+type Condition interface {
+	Node
+	IsCondition()
+}
+
+//go:generate go run github.com/berquerant/marker@v0.1.4 -method IsCondition -type Comparison,And,Or,Not -output ast_marker_condition_generated.go
+
+type Comparison struct {
+	Left  Operand
+	Op    Operator
+	Right Operand
+}
+
+func (c *Comparison) String() string {
+	return fmt.Sprintf("Comparison(%v %s %v)", c.Left, c.Op, c.Right)
+}
+
+func NewComparison(left Operand, op Operator, right Operand) *Comparison {
+	return &Comparison{
+		Left:  left,
+		Op:    op,
+		Right: right,
+	}
+}
+
+type And struct {
+	Left  Condition
+	Right Condition
+}
+
+func NewAnd(left, right Condition) *And {
+	return &And{
+		Left:  left,
+		Right: right,
+	}
+}
+
+type Or struct {
+	Left  Condition
+	Right Condition
+}
+
+func NewOr(left, right Condition) *Or {
+	return &Or{
+		Left:  left,
+		Right: right,
+	}
+}
+
+type Not struct {
+	Cond Condition
+}
+
+func NewNot(cond Condition) *Not {
+	return &Not{
+		Cond: cond,
+	}
+}
+
+// Order is an element of ORDER BY clause.
+type Order struct {
+	Col  *ColumnRef
+	Desc bool
+}
+
+func NewOrder(col *ColumnRef, desc bool) *Order {
+	return &Order{
+		Col:  col,
+		Desc: desc,
+	}
+}
@@ -0,0 +1,329 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/target"
+)
+
+// Query is a statement compiled into joiny terms.
+type Query struct {
+	// Sources are the files to be joined, Sources[0] is the source 1.
+	Sources []*Source
+	JoinKey *joinkey.JoinKey
+	Target  *target.Target
+	// Filters are the conditions from WHERE clause other than joins.
+	Filters []*Filter
+	Orders  []*OrderKey
+	// Limit is negative if no limit.
+	Limit int
+	// Optional are the sources joined by LEFT JOIN, one-based.
+	Optional []int
+}
+
+// Filter compares the column with the literal.
+type Filter struct {
+	Loc   *target.Location
+	Op    Operator
+	Value string
+}
+
+func (f *Filter) String() string { return fmt.Sprintf("Filter(%v %s %s)", f.Loc, f.Op, f.Value) }
+
+// Match returns true if v satisfies the filter.
+func (f *Filter) Match(v string) bool {
+	c := Compare(v, f.Value)
+	switch f.Op {
+	case OpEqual:
+		return c == 0
+	case OpNotEqual:
+		return c != 0
+	case OpLess:
+		return c < 0
+	case OpLessEqual:
+		return c <= 0
+	case OpGreater:
+		return c > 0
+	case OpGreaterEqual:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+type OrderKey struct {
+	Loc  *target.Location
+	Desc bool
+}
+
+func (o *OrderKey) String() string { return fmt.Sprintf("OrderKey(%v, %t)", o.Loc, o.Desc) }
+
+// Compare compares values numerically if both are numbers, otherwise lexicographically.
+func Compare(a, b string) int {
+	x, xErr := strconv.ParseFloat(a, 64)
+	y, yErr := strconv.ParseFloat(b, 64)
+	if xErr == nil && yErr == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+var (
+	ErrUnsupported    = errors.New("Unsupported")
+	ErrUnknownAlias   = errors.New("UnknownAlias")
+	ErrDuplicateAlias = errors.New("DuplicateAlias")
+)
+
+// Compile translates the statement into the join key, the target and the others.
+func Compile(stmt *Statement) (*Query, error) {
+	c := &compiler{
+		aliases: make(map[string]int),
+	}
+	return c.compile(stmt)
+}
+
+type compiler struct {
+	sources   []*Source
+	aliases   map[string]int // alias to source number
+	relations []*joinkey.Relation
+	filters   []*Filter
+	optional  []int
+}
+
+func (c *compiler) compile(stmt *Statement) (*Query, error) {
+	if err := c.addSource(stmt.From); err != nil {
+		return nil, err
+	}
+	for _, j := range stmt.Joins {
+		if j.Type != InnerJoin && j.Type != LeftJoin {
+			return nil, fmt.Errorf("%w: %s JOIN %s, only INNER JOIN and LEFT JOIN are available", ErrUnsupported, j.Type, j.Source.Path)
+		}
+		if j.On == nil {
+			return nil, fmt.Errorf("%w: JOIN %s without ON, joins require equality conditions", ErrUnsupported, j.Source.Path)
+		}
+		if err := c.addSource(j.Source); err != nil {
+			return nil, err
+		}
+	}
+	for i, j := range stmt.Joins {
+		if j.Type == LeftJoin {
+			src := i + 2 // FROM is the source 1
+			if err := c.checkLeftJoin(j.On, src); err != nil {
+				return nil, fmt.Errorf("LEFT JOIN %s: %w", j.Source.Path, err)
+			}
+			c.optional = append(c.optional, src)
+		}
+		if err := c.addCondition(j.On, true); err != nil {
+			return nil, fmt.Errorf("ON %s: %w", j.Source.Path, err)
+		}
+	}
+	if stmt.Where != nil {
+		if err := c.addCondition(stmt.Where, false); err != nil {
+			return nil, fmt.Errorf("WHERE: %w", err)
+		}
+	}
+
+	tgt, err := c.target(stmt.Columns)
+	if err != nil {
+		return nil, err
+	}
+	var orders []*OrderKey
+	for _, o := range stmt.OrderBy {
+		loc, err := c.location(o.Col)
+		if err != nil {
+			return nil, fmt.Errorf("ORDER BY: %w", err)
+		}
+		orders = append(orders, &OrderKey{
+			Loc:  loc,
+			Desc: o.Desc,
+		})
+	}
+
+	relations := c.relations
+	if len(relations) == 0 {
+		if len(c.sources) > 1 {
+			return nil, fmt.Errorf("%w: no join conditions for %d sources", ErrUnsupported, len(c.sources))
+		}
+		// identity join
+		relations = []*joinkey.Relation{
+			joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(1, 1)),
+		}
+	}
+
+	return &Query{
+		Sources:  c.sources,
+		JoinKey:  joinkey.NewJoinKey(relations),
+		Target:   tgt,
+		Filters:  c.filters,
+		Orders:   orders,
+		Limit:    stmt.Limit,
+		Optional: c.optional,
+	}, nil
+}
+
+// checkLeftJoin checks that the condition of LEFT JOIN is an equality between the joined source and a preceding source,
+// and the preceding joins do not refer the joined source.
+// The rows of the preceding sources without the partners are kept by the single relation.
+func (c *compiler) checkLeftJoin(cond Condition, src int) error {
+	for _, r := range c.relations {
+		if r.Left.Src == src || r.Right.Src == src {
+			return fmt.Errorf("%w: %s refers the source before LEFT JOIN", ErrUnsupported, r.Expr())
+		}
+	}
+	unsupported := fmt.Errorf("%w: %v, ON of LEFT JOIN should be an equality between a column of the joined source and a column of a preceding source", ErrUnsupported, cond)
+	cmp, ok := cond.(*Comparison)
+	if !ok || cmp.Op != OpEqual {
+		return unsupported
+	}
+	lRef, lIsRef := cmp.Left.(*ColumnRef)
+	rRef, rIsRef := cmp.Right.(*ColumnRef)
+	if !lIsRef || !rIsRef {
+		return unsupported
+	}
+	left, err := c.location(lRef)
+	if err != nil {
+		return err
+	}
+	right, err := c.location(rRef)
+	if err != nil {
+		return err
+	}
+	if (left.Src == src && right.Src < src) || (right.Src == src && left.Src < src) {
+		return nil
+	}
+	return unsupported
+}
+
+func (c *compiler) addSource(src *Source) error {
+	alias := src.Alias
+	if alias == "" {
+		base := filepath.Base(src.Path)
+		alias = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if _, found := c.aliases[alias]; found {
+		return fmt.Errorf("%w: %s (%s), specify another alias by AS", ErrDuplicateAlias, alias, src.Path)
+	}
+	c.sources = append(c.sources, NewSource(src.Path, alias))
+	c.aliases[alias] = len(c.sources)
+	return nil
+}
+
+func (c *compiler) location(ref *ColumnRef) (*target.Location, error) {
+	src, found := c.aliases[ref.Alias]
+	if !found {
+		return nil, fmt.Errorf("%w: %s in %s.%d", ErrUnknownAlias, ref.Alias, ref.Alias, ref.Col)
+	}
+	return target.NewLocation(src, ref.Col), nil
+}
+
+// addCondition adds conjunctive comparisons.
+// Equalities between columns become relations, comparisons between a column and a literal become filters.
+func (c *compiler) addCondition(cond Condition, isOn bool) error {
+	switch cond := cond.(type) {
+	case *And:
+		if err := c.addCondition(cond.Left, isOn); err != nil {
+			return err
+		}
+		return c.addCondition(cond.Right, isOn)
+	case *Or:
+		return fmt.Errorf("%w: OR, conditions should be combined by AND", ErrUnsupported)
+	case *Not:
+		return fmt.Errorf("%w: NOT, conditions should be combined by AND", ErrUnsupported)
+	case *Comparison:
+		return c.addComparison(cond, isOn)
+	default:
+		return fmt.Errorf("%w: condition %v", ErrUnsupported, cond)
+	}
+}
+
+func (c *compiler) addComparison(cmp *Comparison, isOn bool) error {
+	lRef, lIsRef := cmp.Left.(*ColumnRef)
+	rRef, rIsRef := cmp.Right.(*ColumnRef)
+	switch {
+	case lIsRef && rIsRef:
+		if cmp.Op != OpEqual {
+			return fmt.Errorf("%w: %v, columns can be compared only by =", ErrUnsupported, cmp)
+		}
+		left, err := c.location(lRef)
+		if err != nil {
+			return err
+		}
+		right, err := c.location(rRef)
+		if err != nil {
+			return err
+		}
+		c.relations = append(c.relations, joinkey.NewRelation(
+			joinkey.NewLocation(left.Src, left.Col),
+			joinkey.NewLocation(right.Src, right.Col),
+		))
+		return nil
+	case isOn:
+		return fmt.Errorf("%w: %v, ON supports only equalities between columns, move it into WHERE", ErrUnsupported, cmp)
+	case lIsRef:
+		return c.addFilter(lRef, cmp.Op, cmp.Right)
+	case rIsRef:
+		return c.addFilter(rRef, cmp.Op.Flip(), cmp.Left)
+	default:
+		return fmt.Errorf("%w: %v, comparison should contain a column", ErrUnsupported, cmp)
+	}
+}
+
+func (c *compiler) addFilter(ref *ColumnRef, op Operator, operand Operand) error {
+	lit, ok := operand.(*Literal)
+	if !ok {
+		return fmt.Errorf("%w: operand %v", ErrUnsupported, operand)
+	}
+	loc, err := c.location(ref)
+	if err != nil {
+		return err
+	}
+	c.filters = append(c.filters, &Filter{
+		Loc:   loc,
+		Op:    op,
+		Value: lit.Value,
+	})
+	return nil
+}
+
+func (c *compiler) target(columns []Column) (*target.Target, error) {
+	if columns == nil { // *
+		r := make([]target.Range, len(c.sources))
+		for i := range r {
+			r[i] = target.NewLeft(target.NewLocation(i+1, 1))
+		}
+		return target.NewTarget(r), nil
+	}
+
+	r := make([]target.Range, len(columns))
+	for i, col := range columns {
+		switch col := col.(type) {
+		case *ColumnRef:
+			loc, err := c.location(col)
+			if err != nil {
+				return nil, fmt.Errorf("SELECT: %w", err)
+			}
+			r[i] = target.NewSingle(loc)
+		case *AllColumns:
+			src, found := c.aliases[col.Alias]
+			if !found {
+				return nil, fmt.Errorf("SELECT: %w: %s in %s.*", ErrUnknownAlias, col.Alias, col.Alias)
+			}
+			r[i] = target.NewLeft(target.NewLocation(src, 1))
+		default:
+			return nil, fmt.Errorf("SELECT: %w: column %v", ErrUnsupported, col)
+		}
+	}
+	return target.NewTarget(r), nil
+}
@@ -0,0 +1,181 @@
+package sql_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/sql"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	parse := func(t *testing.T, input string) *sql.Statement {
+		t.Helper()
+		lex := sql.NewLexer(bytes.NewBufferString(input))
+		_ = sql.Parse(lex)
+		if err := lex.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return lex.Statement
+	}
+
+	for _, tc := range []struct {
+		title string
+		input string
+		want  *sql.Query
+	}{
+		{
+			title: "single source",
+			input: "SELECT * FROM account.csv",
+			want: &sql.Query{
+				Sources: []*sql.Source{sql.NewSource("account.csv", "account")},
+				JoinKey: joinkey.NewJoinKey([]*joinkey.Relation{
+					joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(1, 1)),
+				}),
+				Target: target.NewTarget([]target.Range{
+					target.NewLeft(target.NewLocation(1, 1)),
+				}),
+				Limit: -1,
+			},
+		},
+		{
+			title: "join",
+			input: `SELECT a.1, d.* FROM account.csv a JOIN department.csv d ON a.3 = d.2 JOIN ext.csv ON d.3 = ext.1
+WHERE a.1 > 1 AND 'HR' = d.2 AND a.2 = ext.2 ORDER BY d.1 DESC LIMIT 3`,
+			want: &sql.Query{
+				Sources: []*sql.Source{
+					sql.NewSource("account.csv", "a"),
+					sql.NewSource("department.csv", "d"),
+					sql.NewSource("ext.csv", "ext"),
+				},
+				JoinKey: joinkey.NewJoinKey([]*joinkey.Relation{
+					joinkey.NewRelation(joinkey.NewLocation(1, 3), joinkey.NewLocation(2, 2)),
+					joinkey.NewRelation(joinkey.NewLocation(2, 3), joinkey.NewLocation(3, 1)),
+					joinkey.NewRelation(joinkey.NewLocation(1, 2), joinkey.NewLocation(3, 2)),
+				}),
+				Target: target.NewTarget([]target.Range{
+					target.NewSingle(target.NewLocation(1, 1)),
+					target.NewLeft(target.NewLocation(2, 1)),
+				}),
+				Filters: []*sql.Filter{
+					{
+						Loc:   target.NewLocation(1, 1),
+						Op:    sql.OpGreater,
+						Value: "1",
+					},
+					{
+						Loc:   target.NewLocation(2, 2),
+						Op:    sql.OpEqual,
+						Value: "HR",
+					},
+				},
+				Orders: []*sql.OrderKey{
+					{
+						Loc:  target.NewLocation(2, 1),
+						Desc: true,
+					},
+				},
+				Limit: 3,
+			},
+		},
+		{
+			title: "left join",
+			input: "SELECT a.1, d.3 FROM account.csv a LEFT OUTER JOIN department.csv d ON d.2 = a.3 JOIN ext.csv x ON a.1 = x.1",
+			want: &sql.Query{
+				Sources: []*sql.Source{
+					sql.NewSource("account.csv", "a"),
+					sql.NewSource("department.csv", "d"),
+					sql.NewSource("ext.csv", "x"),
+				},
+				JoinKey: joinkey.NewJoinKey([]*joinkey.Relation{
+					joinkey.NewRelation(joinkey.NewLocation(2, 2), joinkey.NewLocation(1, 3)),
+					joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(3, 1)),
+				}),
+				Target: target.NewTarget([]target.Range{
+					target.NewSingle(target.NewLocation(1, 1)),
+					target.NewSingle(target.NewLocation(2, 3)),
+				}),
+				Optional: []int{2},
+				Limit:    -1,
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := sql.Compile(parse(t, tc.input))
+			assert.Nil(t, err)
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+		})
+	}
+
+	for _, tc := range []struct {
+		title string
+		input string
+		err   error
+	}{
+		{
+			title: "right join",
+			input: "SELECT * FROM a.csv RIGHT JOIN b.csv ON a.1 = b.1",
+			err:   sql.ErrUnsupported,
+		},
+		{
+			title: "left join with and",
+			input: "SELECT * FROM a.csv LEFT JOIN b.csv ON a.1 = b.1 AND a.2 = b.2",
+			err:   sql.ErrUnsupported,
+		},
+		{
+			title: "left join without preceding source",
+			input: "SELECT * FROM a.csv LEFT JOIN b.csv ON b.1 = b.2",
+			err:   sql.ErrUnsupported,
+		},
+		{
+			title: "left join referred by preceding join",
+			input: "SELECT * FROM a.csv JOIN c.csv ON a.1 = c.1 AND b.1 = c.2 LEFT JOIN b.csv ON a.1 = b.1",
+			err:   sql.ErrUnsupported,
+		},
+		{
+			title: "cross join",
+			input: "SELECT * FROM a.csv CROSS JOIN b.csv",
+			err:   sql.ErrUnsupported,
+		},
+		{
+			title: "or",
+			input: "SELECT * FROM a.csv WHERE a.1 = 1 OR a.1 = 2",
+			err:   sql.ErrUnsupported,
+		},
+		{
+			title: "literal in on",
+			input: "SELECT * FROM a.csv JOIN b.csv ON a.1 = 'x'",
+			err:   sql.ErrUnsupported,
+		},
+		{
+			title: "unknown alias",
+			input: "SELECT c.1 FROM a.csv JOIN b.csv ON a.1 = b.1",
+			err:   sql.ErrUnknownAlias,
+		},
+		{
+			title: "duplicate alias",
+			input: "SELECT * FROM a.csv JOIN x/a.csv ON a.1 = a.1",
+			err:   sql.ErrDuplicateAlias,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			_, err := sql.Compile(parse(t, tc.input))
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		f := &sql.Filter{
+			Op:    sql.OpLess,
+			Value: "10",
+		}
+		assert.True(t, f.Match("9"))
+		assert.False(t, f.Match("10"))
+		assert.False(t, f.Match("x"))
+	})
+}
@@ -0,0 +1,257 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/berquerant/joiny/logx"
+	"github.com/berquerant/ybase"
+)
+
+func Parse(lexer *Lexer) int {
+	logx.C(logx.Parser).Debug("Begin parse sql")
+	defer func() {
+		logx.C(logx.Parser).Debug("End parse sql", logx.Any("statement", lexer.Statement))
+	}()
+	return yyParse(lexer)
+}
+
+var keywords = map[string]int{
+	"SELECT": SELECT,
+	"FROM":   FROM,
+	"JOIN":   JOIN,
+	"INNER":  INNER,
+	"LEFT":   LEFT,
+	"RIGHT":  RIGHT,
+	"FULL":   FULL,
+	"OUTER":  OUTER,
+	"CROSS":  CROSS,
+	"ON":     ON,
+	"WHERE":  WHERE,
+	"ORDER":  ORDER,
+	"BY":     BY,
+	"ASC":    ASC,
+	"DESC":   DESC,
+	"LIMIT":  LIMIT,
+	"AS":     AS,
+	"AND":    AND,
+	"OR":     OR,
+	"NOT":    NOT,
+}
+
+var (
+	ErrUnterminatedString = errors.New("UnterminatedString")
+	ErrUnexpectedChar     = errors.New("UnexpectedChar")
+)
+
+// tokenScanner remembers the last token because a file path follows FROM and JOIN.
+type tokenScanner struct {
+	last int
+}
+
+func (s *tokenScanner) scan(r ybase.Reader) int {
+	var t int
+	if s.last == FROM || s.last == JOIN {
+		t = scanPath(r)
+	} else {
+		t = ScanToken(r)
+	}
+	s.last = t
+	return t
+}
+
+func isIdentHead(c rune) bool { return c == '_' || unicode.IsLetter(c) }
+func isIdentTail(c rune) bool { return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) }
+func isPathChar(c rune) bool {
+	return c != ybase.EOF && !unicode.IsSpace(c) && c != ',' && c != '(' && c != ')' && c != ';'
+}
+
+// scanPath scans a file path, quoted or bare.
+func scanPath(r ybase.Reader) int {
+	r.DiscardWhile(unicode.IsSpace)
+	switch r.Peek() {
+	case '\'', '"':
+		return scanString(r)
+	default:
+		r.NextWhile(isPathChar)
+		if r.Buffer() == "" {
+			return ScanToken(r)
+		}
+		return PATH
+	}
+}
+
+func scanString(r ybase.Reader) int {
+	quote := r.Next()
+	for {
+		switch r.Next() {
+		case ybase.EOF:
+			r.Errorf(ErrUnterminatedString, "Unterminated string", slog.String("value", r.Buffer()))
+			return ybase.EOF
+		case quote:
+			if r.Peek() != quote {
+				return STRING
+			}
+			_ = r.Next() // escaped quote
+		}
+	}
+}
+
+func scanNumber(r ybase.Reader) int {
+	if r.Peek() == '-' {
+		_ = r.Next()
+	}
+	r.NextWhile(unicode.IsDigit)
+	if r.Peek() == '.' {
+		_ = r.Next()
+		r.NextWhile(unicode.IsDigit)
+	}
+	if v := r.Buffer(); v == "-" || v == "-." {
+		r.Errorf(ErrUnexpectedChar, "Invalid number", slog.String("value", v))
+		return ybase.EOF
+	}
+	return NUMBER
+}
+
+func ScanToken(r ybase.Reader) int {
+	r.DiscardWhile(unicode.IsSpace)
+	c := r.Peek()
+	switch c {
+	case ybase.EOF:
+		return ybase.EOF
+	case '.':
+		_ = r.Next()
+		return DOT
+	case ',':
+		_ = r.Next()
+		return COMMA
+	case '*':
+		_ = r.Next()
+		return STAR
+	case '(':
+		_ = r.Next()
+		return LPAREN
+	case ')':
+		_ = r.Next()
+		return RPAREN
+	case ';':
+		_ = r.Next()
+		return SEMICOLON
+	case '=':
+		_ = r.Next()
+		return EQUAL
+	case '!':
+		_ = r.Next()
+		if r.Peek() != '=' {
+			r.Errorf(ErrUnexpectedChar, "Expected != ", slog.String("value", r.Buffer()))
+			return ybase.EOF
+		}
+		_ = r.Next()
+		return NOT_EQUAL
+	case '<':
+		_ = r.Next()
+		switch r.Peek() {
+		case '=':
+			_ = r.Next()
+			return LESS_EQUAL
+		case '>':
+			_ = r.Next()
+			return NOT_EQUAL
+		default:
+			return LESS
+		}
+	case '>':
+		_ = r.Next()
+		if r.Peek() == '=' {
+			_ = r.Next()
+			return GREATER_EQUAL
+		}
+		return GREATER
+	case '\'', '"':
+		return scanString(r)
+	}
+
+	switch {
+	case c == '-' || unicode.IsDigit(c):
+		return scanNumber(r)
+	case isIdentHead(c):
+		r.NextWhile(isIdentTail)
+		if t, ok := keywords[strings.ToUpper(r.Buffer())]; ok {
+			return t
+		}
+		return IDENT
+	default:
+		r.Errorf(ErrUnexpectedChar, "Unexpected character", slog.String("value", string(c)))
+		return ybase.EOF
+	}
+}
+
+// Unquote removes the quotes from the string literal.
+func Unquote(v string) string {
+	if len(v) < 2 {
+		return v
+	}
+	quote := v[:1]
+	return strings.ReplaceAll(v[1:len(v)-1], quote+quote, quote)
+}
+
+type Lexer struct {
+	ybase.Lexer
+	Statement *Statement
+}
+
+func NewLexer(r io.Reader) *Lexer {
+	yyErrorVerbose = true
+	debug := func(msg string, v ...any) {
+		logx.C(logx.Parser).Debug(fmt.Sprintf(msg, v...))
+	}
+	s := &tokenScanner{}
+	return &Lexer{
+		Lexer: ybase.NewLexer(ybase.NewScanner(
+			ybase.NewReader(r, debug),
+			s.scan,
+		)),
+	}
+}
+
+func (l *Lexer) ParseUint(value string) uint {
+	ui, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		l.Errorf(err, "Cannot parse", slog.String("value", value))
+		return 0
+	}
+	return uint(ui)
+}
+
+var ErrInvalidColumn = errors.New("InvalidColumn")
+
+// ParseColumn parses a column number, it should be a natural number.
+func (l *Lexer) ParseColumn(value string) int {
+	ui, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || ui == 0 {
+		l.Errorf(ErrInvalidColumn, "Column should be a natural number", slog.String("value", value))
+		return 0
+	}
+	return int(ui)
+}
+
+func (l *Lexer) Lex(lval *yySymType) int {
+	return l.DoLex(func(tok ybase.Token) {
+		lval.token = tok
+	})
+}
+
+// Debug enables the debug output of the generated parser by the level
+// if the parser component of logx logs at the debug level.
+func (*Lexer) Debug(level int) {
+	if level > 0 && logx.C(logx.Parser).Enabled(logx.Ldebug) {
+		yyDebug = level
+		return
+	}
+	yyDebug = 0
+}
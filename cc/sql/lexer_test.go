@@ -0,0 +1,112 @@
+package sql_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/sql"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		input string
+		want  *sql.Statement
+	}{
+		{
+			title: "select all",
+			input: "select * from a.csv",
+			want:  sql.NewStatement(nil, sql.NewSource("a.csv", ""), nil, nil, nil, -1),
+		},
+		{
+			title: "join",
+			input: "SELECT a.1, b.* FROM dir/account.csv AS a JOIN 'dept file.csv' b ON a.3 = b.2",
+			want: sql.NewStatement(
+				[]sql.Column{
+					sql.NewColumnRef("a", 1),
+					sql.NewAllColumns("b"),
+				},
+				sql.NewSource("dir/account.csv", "a"),
+				[]*sql.Join{
+					sql.NewJoin(
+						sql.InnerJoin,
+						sql.NewSource("dept file.csv", "b"),
+						sql.NewComparison(sql.NewColumnRef("a", 3), sql.OpEqual, sql.NewColumnRef("b", 2)),
+					),
+				},
+				nil, nil, -1,
+			),
+		},
+		{
+			title: "full",
+			input: `SELECT a.2 FROM a.csv a INNER JOIN b.csv b ON a.1 = b.1 LEFT OUTER JOIN c.csv c ON b.2 = c.1
+WHERE a.3 >= 10 AND 'x''y' <> b.2 ORDER BY a.2 DESC, b.1 LIMIT 5;`,
+			want: sql.NewStatement(
+				[]sql.Column{
+					sql.NewColumnRef("a", 2),
+				},
+				sql.NewSource("a.csv", "a"),
+				[]*sql.Join{
+					sql.NewJoin(
+						sql.InnerJoin,
+						sql.NewSource("b.csv", "b"),
+						sql.NewComparison(sql.NewColumnRef("a", 1), sql.OpEqual, sql.NewColumnRef("b", 1)),
+					),
+					sql.NewJoin(
+						sql.LeftJoin,
+						sql.NewSource("c.csv", "c"),
+						sql.NewComparison(sql.NewColumnRef("b", 2), sql.OpEqual, sql.NewColumnRef("c", 1)),
+					),
+				},
+				sql.NewAnd(
+					sql.NewComparison(sql.NewColumnRef("a", 3), sql.OpGreaterEqual, sql.NewLiteral("10")),
+					sql.NewComparison(sql.NewLiteral("x'y"), sql.OpNotEqual, sql.NewColumnRef("b", 2)),
+				),
+				[]*sql.Order{
+					sql.NewOrder(sql.NewColumnRef("a", 2), true),
+					sql.NewOrder(sql.NewColumnRef("b", 1), false),
+				},
+				5,
+			),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			lex := sql.NewLexer(bytes.NewBufferString(tc.input))
+			_ = sql.Parse(lex)
+			assert.Nil(t, lex.Err())
+			assert.Equal(t, "", cmp.Diff(tc.want, lex.Statement))
+		})
+	}
+
+	for _, tc := range []struct {
+		title string
+		input string
+	}{
+		{
+			title: "no from",
+			input: "SELECT a.1",
+		},
+		{
+			title: "zero column",
+			input: "SELECT a.0 FROM a.csv",
+		},
+		{
+			title: "unterminated string",
+			input: "SELECT * FROM 'a.csv",
+		},
+		{
+			title: "unknown operator",
+			input: "SELECT * FROM a.csv WHERE a.1 ~ 1",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			lex := sql.NewLexer(bytes.NewBufferString(tc.input))
+			_ = sql.Parse(lex)
+			assert.NotNil(t, lex.Err())
+		})
+	}
+}
@@ -0,0 +1,890 @@
+// Code generated by goyacc -o cc/sql/sql.go -v cc/sql/sql.output cc/sql/sql.y. DO NOT EDIT.
+
+//line cc/sql/sql.y:2
+package sql
+
+import __yyfmt__ "fmt"
+
+//line cc/sql/sql.y:2
+
+import "github.com/berquerant/ybase"
+
+//line cc/sql/sql.y:7
+type yySymType struct {
+	yys        int
+	statement  *Statement
+	columns    []Column
+	column     Column
+	column_ref *ColumnRef
+	source     *Source
+	joins      []*Join
+	join       *Join
+	join_type  JoinType
+	condition  Condition
+	operand    Operand
+	operator   Operator
+	orders     []*Order
+	order      *Order
+	desc       bool
+	limit      int
+	str        string
+	token      ybase.Token
+}
+
+const SELECT = 57346
+const FROM = 57347
+const JOIN = 57348
+const INNER = 57349
+const LEFT = 57350
+const RIGHT = 57351
+const FULL = 57352
+const OUTER = 57353
+const CROSS = 57354
+const ON = 57355
+const WHERE = 57356
+const ORDER = 57357
+const BY = 57358
+const ASC = 57359
+const DESC = 57360
+const LIMIT = 57361
+const AS = 57362
+const AND = 57363
+const OR = 57364
+const NOT = 57365
+const IDENT = 57366
+const NUMBER = 57367
+const STRING = 57368
+const PATH = 57369
+const DOT = 57370
+const COMMA = 57371
+const STAR = 57372
+const LPAREN = 57373
+const RPAREN = 57374
+const SEMICOLON = 57375
+const EQUAL = 57376
+const NOT_EQUAL = 57377
+const LESS = 57378
+const LESS_EQUAL = 57379
+const GREATER = 57380
+const GREATER_EQUAL = 57381
+
+var yyToknames = [...]string{
+	"$end",
+	"error",
+	"$unk",
+	"SELECT",
+	"FROM",
+	"JOIN",
+	"INNER",
+	"LEFT",
+	"RIGHT",
+	"FULL",
+	"OUTER",
+	"CROSS",
+	"ON",
+	"WHERE",
+	"ORDER",
+	"BY",
+	"ASC",
+	"DESC",
+	"LIMIT",
+	"AS",
+	"AND",
+	"OR",
+	"NOT",
+	"IDENT",
+	"NUMBER",
+	"STRING",
+	"PATH",
+	"DOT",
+	"COMMA",
+	"STAR",
+	"LPAREN",
+	"RPAREN",
+	"SEMICOLON",
+	"EQUAL",
+	"NOT_EQUAL",
+	"LESS",
+	"LESS_EQUAL",
+	"GREATER",
+	"GREATER_EQUAL",
+}
+
+var yyStatenames = [...]string{}
+
+const yyEofCode = 1
+const yyErrCode = 2
+const yyInitialStackSize = 16
+
+//line yacctab:1
+var yyExca = [...]int8{
+	-1, 1,
+	1, -1,
+	-2, 0,
+	-1, 19,
+	6, 20,
+	-2, 30,
+}
+
+const yyPrivate = 57344
+
+const yyLast = 92
+
+var yyAct = [...]int8{
+	35, 68, 36, 39, 12, 65, 7, 54, 55, 56,
+	57, 58, 59, 44, 7, 37, 42, 41, 40, 51,
+	52, 18, 76, 38, 10, 8, 17, 42, 41, 40,
+	73, 4, 15, 14, 18, 62, 11, 66, 60, 61,
+	42, 22, 32, 46, 47, 21, 8, 51, 63, 51,
+	52, 49, 70, 71, 69, 50, 72, 78, 79, 27,
+	28, 29, 30, 34, 31, 43, 25, 75, 45, 9,
+	6, 2, 64, 48, 77, 67, 80, 33, 81, 53,
+	69, 16, 23, 74, 26, 24, 19, 20, 13, 5,
+	3, 1,
+}
+
+var yyPact = [...]int16{
+	67, -1000, 1, 64, -1000, -5, -1000, -1000, 8, 6,
+	22, -4, -1000, 21, -1000, -1000, -1000, -1000, -1000, 52,
+	-1000, -1000, 18, 48, -1000, -8, 59, -1000, 57, 57,
+	57, -1000, -1000, 32, 39, 28, -27, -8, -8, -1000,
+	-1000, -1000, 7, 6, -1000, -1000, -1000, -1000, -28, 12,
+	16, -8, -8, 3, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -2, 9, 54, -1000, -1000, -1000, -7, -1000, 40,
+	-1000, 26, -1000, -1000, -1000, -8, 16, -1000, -1000, -1000,
+	28, -1000,
+}
+
+var yyPgo = [...]int8{
+	0, 91, 90, 89, 70, 3, 4, 88, 87, 86,
+	85, 84, 0, 83, 82, 2, 79, 77, 75, 1,
+	74, 73, 72, 13,
+}
+
+var yyR1 = [...]int8{
+	0, 1, 22, 22, 2, 2, 3, 3, 4, 4,
+	5, 6, 7, 7, 8, 8, 8, 9, 9, 10,
+	11, 11, 11, 11, 11, 11, 23, 23, 13, 13,
+	14, 14, 12, 12, 12, 12, 12, 15, 15, 15,
+	16, 16, 16, 16, 16, 16, 17, 17, 18, 18,
+	19, 20, 20, 20, 21, 21,
+}
+
+var yyR2 = [...]int8{
+	0, 9, 0, 1, 1, 1, 1, 3, 1, 3,
+	3, 2, 1, 1, 0, 1, 2, 0, 2, 4,
+	0, 1, 2, 2, 2, 1, 0, 1, 0, 2,
+	0, 2, 3, 3, 3, 2, 3, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 0, 3, 1, 3,
+	2, 0, 1, 1, 0, 2,
+}
+
+var yyChk = [...]int16{
+	-1000, -1, 4, -2, 30, -3, -4, -5, 24, 5,
+	29, 28, -6, -7, 27, 26, -4, 30, 25, -9,
+	-8, 24, 20, -14, -10, 14, -11, 7, 8, 9,
+	10, 12, 24, -17, 15, -12, -15, 23, 31, -5,
+	26, 25, 24, 6, -23, 11, -23, -23, -21, 19,
+	16, 21, 22, -16, 34, 35, 36, 37, 38, 39,
+	-12, -12, 28, -6, -22, 33, 25, -18, -19, -5,
+	-12, -12, -15, 32, -13, 13, 29, -20, 17, 18,
+	-12, -19,
+}
+
+var yyDef = [...]int8{
+	0, -2, 0, 0, 4, 5, 6, 8, 0, 0,
+	0, 0, 17, 14, 12, 13, 7, 9, 10, -2,
+	11, 15, 0, 46, 18, 0, 0, 21, 26, 26,
+	26, 25, 16, 54, 0, 31, 0, 0, 0, 37,
+	38, 39, 0, 0, 22, 27, 23, 24, 2, 0,
+	0, 0, 0, 0, 40, 41, 42, 43, 44, 45,
+	35, 0, 0, 28, 1, 3, 55, 47, 48, 51,
+	33, 34, 32, 36, 19, 0, 0, 50, 52, 53,
+	29, 49,
+}
+
+var yyTok1 = [...]int8{
+	1,
+}
+
+var yyTok2 = [...]int8{
+	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
+	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
+	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
+	32, 33, 34, 35, 36, 37, 38, 39,
+}
+
+var yyTok3 = [...]int8{
+	0,
+}
+
+var yyErrorMessages = [...]struct {
+	state int
+	token int
+	msg   string
+}{}
+
+//line yaccpar:1
+
+/*	parser for yacc output	*/
+
+var (
+	yyDebug        = 0
+	yyErrorVerbose = false
+)
+
+type yyLexer interface {
+	Lex(lval *yySymType) int
+	Error(s string)
+}
+
+type yyParser interface {
+	Parse(yyLexer) int
+	Lookahead() int
+}
+
+type yyParserImpl struct {
+	lval  yySymType
+	stack [yyInitialStackSize]yySymType
+	char  int
+}
+
+func (p *yyParserImpl) Lookahead() int {
+	return p.char
+}
+
+func yyNewParser() yyParser {
+	return &yyParserImpl{}
+}
+
+const yyFlag = -1000
+
+func yyTokname(c int) string {
+	if c >= 1 && c-1 < len(yyToknames) {
+		if yyToknames[c-1] != "" {
+			return yyToknames[c-1]
+		}
+	}
+	return __yyfmt__.Sprintf("tok-%v", c)
+}
+
+func yyStatname(s int) string {
+	if s >= 0 && s < len(yyStatenames) {
+		if yyStatenames[s] != "" {
+			return yyStatenames[s]
+		}
+	}
+	return __yyfmt__.Sprintf("state-%v", s)
+}
+
+func yyErrorMessage(state, lookAhead int) string {
+	const TOKSTART = 4
+
+	if !yyErrorVerbose {
+		return "syntax error"
+	}
+
+	for _, e := range yyErrorMessages {
+		if e.state == state && e.token == lookAhead {
+			return "syntax error: " + e.msg
+		}
+	}
+
+	res := "syntax error: unexpected " + yyTokname(lookAhead)
+
+	// To match Bison, suggest at most four expected tokens.
+	expected := make([]int, 0, 4)
+
+	// Look for shiftable tokens.
+	base := int(yyPact[state])
+	for tok := TOKSTART; tok-1 < len(yyToknames); tok++ {
+		if n := base + tok; n >= 0 && n < yyLast && int(yyChk[int(yyAct[n])]) == tok {
+			if len(expected) == cap(expected) {
+				return res
+			}
+			expected = append(expected, tok)
+		}
+	}
+
+	if yyDef[state] == -2 {
+		i := 0
+		for yyExca[i] != -1 || int(yyExca[i+1]) != state {
+			i += 2
+		}
+
+		// Look for tokens that we accept or reduce.
+		for i += 2; yyExca[i] >= 0; i += 2 {
+			tok := int(yyExca[i])
+			if tok < TOKSTART || yyExca[i+1] == 0 {
+				continue
+			}
+			if len(expected) == cap(expected) {
+				return res
+			}
+			expected = append(expected, tok)
+		}
+
+		// If the default action is to accept or reduce, give up.
+		if yyExca[i+1] != 0 {
+			return res
+		}
+	}
+
+	for i, tok := range expected {
+		if i == 0 {
+			res += ", expecting "
+		} else {
+			res += " or "
+		}
+		res += yyTokname(tok)
+	}
+	return res
+}
+
+func yylex1(lex yyLexer, lval *yySymType) (char, token int) {
+	token = 0
+	char = lex.Lex(lval)
+	if char <= 0 {
+		token = int(yyTok1[0])
+		goto out
+	}
+	if char < len(yyTok1) {
+		token = int(yyTok1[char])
+		goto out
+	}
+	if char >= yyPrivate {
+		if char < yyPrivate+len(yyTok2) {
+			token = int(yyTok2[char-yyPrivate])
+			goto out
+		}
+	}
+	for i := 0; i < len(yyTok3); i += 2 {
+		token = int(yyTok3[i+0])
+		if token == char {
+			token = int(yyTok3[i+1])
+			goto out
+		}
+	}
+
+out:
+	if token == 0 {
+		token = int(yyTok2[1]) /* unknown char */
+	}
+	if yyDebug >= 3 {
+		__yyfmt__.Printf("lex %s(%d)\n", yyTokname(token), uint(char))
+	}
+	return char, token
+}
+
+func yyParse(yylex yyLexer) int {
+	return yyNewParser().Parse(yylex)
+}
+
+func (yyrcvr *yyParserImpl) Parse(yylex yyLexer) int {
+	var yyn int
+	var yyVAL yySymType
+	var yyDollar []yySymType
+	_ = yyDollar // silence set and not used
+	yyS := yyrcvr.stack[:]
+
+	Nerrs := 0   /* number of errors */
+	Errflag := 0 /* error recovery flag */
+	yystate := 0
+	yyrcvr.char = -1
+	yytoken := -1 // yyrcvr.char translated into internal numbering
+	defer func() {
+		// Make sure we report no lookahead when not parsing.
+		yystate = -1
+		yyrcvr.char = -1
+		yytoken = -1
+	}()
+	yyp := -1
+	goto yystack
+
+ret0:
+	return 0
+
+ret1:
+	return 1
+
+yystack:
+	/* put a state and value onto the stack */
+	if yyDebug >= 4 {
+		__yyfmt__.Printf("char %v in %v\n", yyTokname(yytoken), yyStatname(yystate))
+	}
+
+	yyp++
+	if yyp >= len(yyS) {
+		nyys := make([]yySymType, len(yyS)*2)
+		copy(nyys, yyS)
+		yyS = nyys
+	}
+	yyS[yyp] = yyVAL
+	yyS[yyp].yys = yystate
+
+yynewstate:
+	yyn = int(yyPact[yystate])
+	if yyn <= yyFlag {
+		goto yydefault /* simple state */
+	}
+	if yyrcvr.char < 0 {
+		yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
+	}
+	yyn += yytoken
+	if yyn < 0 || yyn >= yyLast {
+		goto yydefault
+	}
+	yyn = int(yyAct[yyn])
+	if int(yyChk[yyn]) == yytoken { /* valid shift */
+		yyrcvr.char = -1
+		yytoken = -1
+		yyVAL = yyrcvr.lval
+		yystate = yyn
+		if Errflag > 0 {
+			Errflag--
+		}
+		goto yystack
+	}
+
+yydefault:
+	/* default state action */
+	yyn = int(yyDef[yystate])
+	if yyn == -2 {
+		if yyrcvr.char < 0 {
+			yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
+		}
+
+		/* look through exception table */
+		xi := 0
+		for {
+			if yyExca[xi+0] == -1 && int(yyExca[xi+1]) == yystate {
+				break
+			}
+			xi += 2
+		}
+		for xi += 2; ; xi += 2 {
+			yyn = int(yyExca[xi+0])
+			if yyn < 0 || yyn == yytoken {
+				break
+			}
+		}
+		yyn = int(yyExca[xi+1])
+		if yyn < 0 {
+			goto ret0
+		}
+	}
+	if yyn == 0 {
+		/* error ... attempt to resume parsing */
+		switch Errflag {
+		case 0: /* brand new error */
+			yylex.Error(yyErrorMessage(yystate, yytoken))
+			Nerrs++
+			if yyDebug >= 1 {
+				__yyfmt__.Printf("%s", yyStatname(yystate))
+				__yyfmt__.Printf(" saw %s\n", yyTokname(yytoken))
+			}
+			fallthrough
+
+		case 1, 2: /* incompletely recovered error ... try again */
+			Errflag = 3
+
+			/* find a state where "error" is a legal shift action */
+			for yyp >= 0 {
+				yyn = int(yyPact[yyS[yyp].yys]) + yyErrCode
+				if yyn >= 0 && yyn < yyLast {
+					yystate = int(yyAct[yyn]) /* simulate a shift of "error" */
+					if int(yyChk[yystate]) == yyErrCode {
+						goto yystack
+					}
+				}
+
+				/* the current p has no shift on "error", pop stack */
+				if yyDebug >= 2 {
+					__yyfmt__.Printf("error recovery pops state %d\n", yyS[yyp].yys)
+				}
+				yyp--
+			}
+			/* there is no state on the stack with an error shift ... abort */
+			goto ret1
+
+		case 3: /* no shift yet; clobber input char */
+			if yyDebug >= 2 {
+				__yyfmt__.Printf("error recovery discards %s\n", yyTokname(yytoken))
+			}
+			if yytoken == yyEofCode {
+				goto ret1
+			}
+			yyrcvr.char = -1
+			yytoken = -1
+			goto yynewstate /* try again in the same state */
+		}
+	}
+
+	/* reduction by production yyn */
+	if yyDebug >= 2 {
+		__yyfmt__.Printf("reduce %v in:\n\t%v\n", yyn, yyStatname(yystate))
+	}
+
+	yynt := yyn
+	yypt := yyp
+	_ = yypt // guard against "declared and not used"
+
+	yyp -= int(yyR2[yyn])
+	// yyp is now the index of $0. Perform the default action. Iff the
+	// reduced production is ε, $1 is possibly out of range.
+	if yyp+1 >= len(yyS) {
+		nyys := make([]yySymType, len(yyS)*2)
+		copy(nyys, yyS)
+		yyS = nyys
+	}
+	yyVAL = yyS[yyp+1]
+
+	/* consult goto table to find next state */
+	yyn = int(yyR1[yyn])
+	yyg := int(yyPgo[yyn])
+	yyj := yyg + yyS[yyp].yys + 1
+
+	if yyj >= yyLast {
+		yystate = int(yyAct[yyg])
+	} else {
+		yystate = int(yyAct[yyj])
+		if int(yyChk[yystate]) != -yyn {
+			yystate = int(yyAct[yyg])
+		}
+	}
+	// dummy call; replaced with literal code
+	switch yynt {
+
+	case 1:
+		yyDollar = yyS[yypt-9 : yypt+1]
+//line cc/sql/sql.y:57
+		{
+			r := NewStatement(yyDollar[2].columns, yyDollar[4].source, yyDollar[5].joins, yyDollar[6].condition, yyDollar[7].orders, yyDollar[8].limit)
+			yylex.(*Lexer).Statement = r
+			yyVAL.statement = r
+		}
+	case 2:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:64
+		{
+		}
+	case 3:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:65
+		{
+		}
+	case 4:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:68
+		{
+			yyVAL.columns = nil
+		}
+	case 5:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:71
+		{
+			yyVAL.columns = yyDollar[1].columns
+		}
+	case 6:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:76
+		{
+			yyVAL.columns = []Column{yyDollar[1].column}
+		}
+	case 7:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:79
+		{
+			yyVAL.columns = append(yyDollar[1].columns, yyDollar[3].column)
+		}
+	case 8:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:84
+		{
+			yyVAL.column = yyDollar[1].column_ref
+		}
+	case 9:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:87
+		{
+			yyVAL.column = NewAllColumns(yyDollar[1].token.Value())
+		}
+	case 10:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:92
+		{
+			lex := yylex.(*Lexer)
+			yyVAL.column_ref = NewColumnRef(yyDollar[1].token.Value(), lex.ParseColumn(yyDollar[3].token.Value()))
+		}
+	case 11:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:98
+		{
+			yyVAL.source = NewSource(yyDollar[1].str, yyDollar[2].str)
+		}
+	case 12:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:103
+		{
+			yyVAL.str = yyDollar[1].token.Value()
+		}
+	case 13:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:106
+		{
+			yyVAL.str = Unquote(yyDollar[1].token.Value())
+		}
+	case 14:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:111
+		{
+			yyVAL.str = ""
+		}
+	case 15:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:114
+		{
+			yyVAL.str = yyDollar[1].token.Value()
+		}
+	case 16:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:117
+		{
+			yyVAL.str = yyDollar[2].token.Value()
+		}
+	case 17:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:122
+		{
+			yyVAL.joins = nil
+		}
+	case 18:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:125
+		{
+			yyVAL.joins = append(yyDollar[1].joins, yyDollar[2].join)
+		}
+	case 19:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line cc/sql/sql.y:130
+		{
+			yyVAL.join = NewJoin(yyDollar[1].join_type, yyDollar[3].source, yyDollar[4].condition)
+		}
+	case 20:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:135
+		{
+			yyVAL.join_type = InnerJoin
+		}
+	case 21:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:138
+		{
+			yyVAL.join_type = InnerJoin
+		}
+	case 22:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:141
+		{
+			yyVAL.join_type = LeftJoin
+		}
+	case 23:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:144
+		{
+			yyVAL.join_type = RightJoin
+		}
+	case 24:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:147
+		{
+			yyVAL.join_type = FullJoin
+		}
+	case 25:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:150
+		{
+			yyVAL.join_type = CrossJoin
+		}
+	case 26:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:155
+		{
+		}
+	case 27:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:156
+		{
+		}
+	case 28:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:159
+		{
+			yyVAL.condition = nil
+		}
+	case 29:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:162
+		{
+			yyVAL.condition = yyDollar[2].condition
+		}
+	case 30:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:167
+		{
+			yyVAL.condition = nil
+		}
+	case 31:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:170
+		{
+			yyVAL.condition = yyDollar[2].condition
+		}
+	case 32:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:175
+		{
+			yyVAL.condition = NewComparison(yyDollar[1].operand, yyDollar[2].operator, yyDollar[3].operand)
+		}
+	case 33:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:178
+		{
+			yyVAL.condition = NewAnd(yyDollar[1].condition, yyDollar[3].condition)
+		}
+	case 34:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:181
+		{
+			yyVAL.condition = NewOr(yyDollar[1].condition, yyDollar[3].condition)
+		}
+	case 35:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:184
+		{
+			yyVAL.condition = NewNot(yyDollar[2].condition)
+		}
+	case 36:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:187
+		{
+			yyVAL.condition = yyDollar[2].condition
+		}
+	case 37:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:192
+		{
+			yyVAL.operand = yyDollar[1].column_ref
+		}
+	case 38:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:195
+		{
+			yyVAL.operand = NewLiteral(Unquote(yyDollar[1].token.Value()))
+		}
+	case 39:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:198
+		{
+			yyVAL.operand = NewLiteral(yyDollar[1].token.Value())
+		}
+	case 40:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:203
+		{
+			yyVAL.operator = OpEqual
+		}
+	case 41:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:206
+		{
+			yyVAL.operator = OpNotEqual
+		}
+	case 42:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:209
+		{
+			yyVAL.operator = OpLess
+		}
+	case 43:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:212
+		{
+			yyVAL.operator = OpLessEqual
+		}
+	case 44:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:215
+		{
+			yyVAL.operator = OpGreater
+		}
+	case 45:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:218
+		{
+			yyVAL.operator = OpGreaterEqual
+		}
+	case 46:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:223
+		{
+			yyVAL.orders = nil
+		}
+	case 47:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:226
+		{
+			yyVAL.orders = yyDollar[3].orders
+		}
+	case 48:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:231
+		{
+			yyVAL.orders = []*Order{yyDollar[1].order}
+		}
+	case 49:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/sql/sql.y:234
+		{
+			yyVAL.orders = append(yyDollar[1].orders, yyDollar[3].order)
+		}
+	case 50:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:239
+		{
+			yyVAL.order = NewOrder(yyDollar[1].column_ref, yyDollar[2].desc)
+		}
+	case 51:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:244
+		{
+			yyVAL.desc = false
+		}
+	case 52:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:247
+		{
+			yyVAL.desc = false
+		}
+	case 53:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line cc/sql/sql.y:250
+		{
+			yyVAL.desc = true
+		}
+	case 54:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/sql/sql.y:255
+		{
+			yyVAL.limit = -1
+		}
+	case 55:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line cc/sql/sql.y:258
+		{
+			lex := yylex.(*Lexer)
+			yyVAL.limit = int(lex.ParseUint(yyDollar[2].token.Value()))
+		}
+	}
+	goto yystack /* stack new state and value */
+}
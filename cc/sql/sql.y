@@ -0,0 +1,261 @@
+%{
+package sql
+
+import "github.com/berquerant/ybase"
+%}
+
+%union{
+  statement *Statement
+  columns []Column
+  column Column
+  column_ref *ColumnRef
+  source *Source
+  joins []*Join
+  join *Join
+  join_type JoinType
+  condition Condition
+  operand Operand
+  operator Operator
+  orders []*Order
+  order *Order
+  desc bool
+  limit int
+  str string
+  token ybase.Token
+}
+
+%type <statement> statement
+%type <columns> select_list column_list
+%type <column> column
+%type <column_ref> column_ref
+%type <source> source
+%type <str> path alias_opt
+%type <joins> join_list
+%type <join> join
+%type <join_type> join_type
+%type <condition> condition on_opt where_opt
+%type <operand> operand
+%type <operator> operator
+%type <orders> order_opt order_list
+%type <order> order
+%type <desc> direction_opt
+%type <limit> limit_opt
+
+%token <token> SELECT FROM JOIN INNER LEFT RIGHT FULL OUTER CROSS ON WHERE ORDER BY ASC DESC LIMIT AS
+%token <token> AND OR NOT
+%token <token> IDENT NUMBER STRING PATH
+%token <token> DOT COMMA STAR LPAREN RPAREN SEMICOLON
+%token <token> EQUAL NOT_EQUAL LESS LESS_EQUAL GREATER GREATER_EQUAL
+
+%left OR
+%left AND
+%right NOT
+
+%%
+
+statement:
+  SELECT select_list FROM source join_list where_opt order_opt limit_opt semicolon_opt {
+    r := NewStatement($2, $4, $5, $6, $7, $8)
+    yylex.(*Lexer).Statement = r
+    $$ = r
+  }
+
+semicolon_opt:
+  /* empty */ {}
+  | SEMICOLON {}
+
+select_list:
+  STAR {
+    $$ = nil
+  }
+  | column_list {
+    $$ = $1
+  }
+
+column_list:
+  column {
+    $$ = []Column{$1}
+  }
+  | column_list COMMA column {
+    $$ = append($1, $3)
+  }
+
+column:
+  column_ref {
+    $$ = $1
+  }
+  | IDENT DOT STAR {
+    $$ = NewAllColumns($1.Value())
+  }
+
+column_ref:
+  IDENT DOT NUMBER {
+    lex := yylex.(*Lexer)
+    $$ = NewColumnRef($1.Value(), lex.ParseColumn($3.Value()))
+  }
+
+source:
+  path alias_opt {
+    $$ = NewSource($1, $2)
+  }
+
+path:
+  PATH {
+    $$ = $1.Value()
+  }
+  | STRING {
+    $$ = Unquote($1.Value())
+  }
+
+alias_opt:
+  /* empty */ {
+    $$ = ""
+  }
+  | IDENT {
+    $$ = $1.Value()
+  }
+  | AS IDENT {
+    $$ = $2.Value()
+  }
+
+join_list:
+  /* empty */ {
+    $$ = nil
+  }
+  | join_list join {
+    $$ = append($1, $2)
+  }
+
+join:
+  join_type JOIN source on_opt {
+    $$ = NewJoin($1, $3, $4)
+  }
+
+join_type:
+  /* empty */ {
+    $$ = InnerJoin
+  }
+  | INNER {
+    $$ = InnerJoin
+  }
+  | LEFT outer_opt {
+    $$ = LeftJoin
+  }
+  | RIGHT outer_opt {
+    $$ = RightJoin
+  }
+  | FULL outer_opt {
+    $$ = FullJoin
+  }
+  | CROSS {
+    $$ = CrossJoin
+  }
+
+outer_opt:
+  /* empty */ {}
+  | OUTER {}
+
+on_opt:
+  /* empty */ {
+    $$ = nil
+  }
+  | ON condition {
+    $$ = $2
+  }
+
+where_opt:
+  /* empty */ {
+    $$ = nil
+  }
+  | WHERE condition {
+    $$ = $2
+  }
+
+condition:
+  operand operator operand {
+    $$ = NewComparison($1, $2, $3)
+  }
+  | condition AND condition {
+    $$ = NewAnd($1, $3)
+  }
+  | condition OR condition {
+    $$ = NewOr($1, $3)
+  }
+  | NOT condition {
+    $$ = NewNot($2)
+  }
+  | LPAREN condition RPAREN {
+    $$ = $2
+  }
+
+operand:
+  column_ref {
+    $$ = $1
+  }
+  | STRING {
+    $$ = NewLiteral(Unquote($1.Value()))
+  }
+  | NUMBER {
+    $$ = NewLiteral($1.Value())
+  }
+
+operator:
+  EQUAL {
+    $$ = OpEqual
+  }
+  | NOT_EQUAL {
+    $$ = OpNotEqual
+  }
+  | LESS {
+    $$ = OpLess
+  }
+  | LESS_EQUAL {
+    $$ = OpLessEqual
+  }
+  | GREATER {
+    $$ = OpGreater
+  }
+  | GREATER_EQUAL {
+    $$ = OpGreaterEqual
+  }
+
+order_opt:
+  /* empty */ {
+    $$ = nil
+  }
+  | ORDER BY order_list {
+    $$ = $3
+  }
+
+order_list:
+  order {
+    $$ = []*Order{$1}
+  }
+  | order_list COMMA order {
+    $$ = append($1, $3)
+  }
+
+order:
+  column_ref direction_opt {
+    $$ = NewOrder($1, $2)
+  }
+
+direction_opt:
+  /* empty */ {
+    $$ = false
+  }
+  | ASC {
+    $$ = false
+  }
+  | DESC {
+    $$ = true
+  }
+
+limit_opt:
+  /* empty */ {
+    $$ = -1
+  }
+  | LIMIT NUMBER {
+    lex := yylex.(*Lexer)
+    $$ = int(lex.ParseUint($2.Value()))
+  }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/berquerant/joiny/cc/parseerr"
 	"github.com/berquerant/joiny/cc/target"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
@@ -64,3 +65,63 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseError(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		input       string
+		column      int
+		want        error
+		suggestions []string
+	}{
+		{
+			title:       "unexpected token",
+			input:       "1.2,2,1",
+			column:      6,
+			want:        parseerr.ErrSyntax,
+			suggestions: []string{"1.2,2.1"},
+		},
+		{
+			title:  "unexpected minus",
+			input:  "1.1--2.1",
+			column: 5,
+			want:   parseerr.ErrSyntax,
+		},
+		{
+			title:       "unexpected character",
+			input:       "1:2",
+			column:      2,
+			want:        parseerr.ErrUnexpectedCharacter,
+			suggestions: []string{"1.2"},
+		},
+		{
+			title:       "zero column",
+			input:       "1.0-",
+			column:      3,
+			want:        parseerr.ErrInvalidNumber,
+			suggestions: []string{"1.1-"},
+		},
+		{
+			title:       "zero and one-based",
+			input:       "2.3,0.1-",
+			column:      5,
+			want:        parseerr.ErrInvalidNumber,
+			suggestions: []string{"2.3,1.1-"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			lex := target.NewLexer(bytes.NewBufferString(tc.input))
+			_ = target.Parse(lex)
+			err := lex.Err()
+			assert.ErrorIs(t, err, tc.want)
+			var parseErr *parseerr.Error
+			if !assert.ErrorAs(t, err, &parseErr) {
+				return
+			}
+			assert.Equal(t, tc.input, parseErr.Input)
+			assert.Equal(t, tc.column, parseErr.Column())
+			assert.Equal(t, tc.suggestions, parseErr.Suggestions)
+		})
+	}
+}
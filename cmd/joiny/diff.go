@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
+)
+
+const diffUsage = `Usage: joiny diff [flags] LEFT RIGHT
+
+Compare 2 files by key.
+
+key is a relation between the source 1 (LEFT) and the source 2 (RIGHT), like "1.1=2.1".
+The rows of the same key are paired in the order of appearance.
+Each difference is tagged by only-left, only-right or changed.
+
+Output format:
+  delimited: tag, changed columns separated by ";", and the row, joined by the delimiter.
+    changed emits the left row tagged by changed-left and the right row tagged by changed-right.
+  json: {"kind":tag,"key":key,"left":[columns],"right":[columns],"columns":[changed columns]} per line.
+
+e.g.
+$ cat > yesterday.csv <<EOS
+1,account1,HR
+2,account2,Dev
+3,account3,PR
+EOS
+$ cat > today.csv <<EOS
+1,account1,HR
+2,account2,PR
+4,account4,HR
+EOS
+$ joiny diff yesterday.csv today.csv
+changed-left,3,2,account2,Dev
+changed-right,3,2,account2,PR
+only-left,,3,account3,PR
+only-right,,4,account4,HR
+
+Flags:`
+
+var (
+	errDiffSources = errors.New("DiffSources")
+	errDiffKey     = errors.New("DiffKey")
+)
+
+func runDiff(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	registerCommonFlags(fs)
+	fs.StringVar(key, "k", "", "key")
+	format := fs.String("o", "delimited", "output format: delimited or json")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, diffUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errDiffSources
+	}
+	if err := setupLog(); err != nil {
+		return err
+	}
+
+	w, err := newDiffWriter(*format, os.Stdout)
+	if err != nil {
+		return err
+	}
+	return withFileList(ctx, fs.Args(), func(ctx context.Context, files []source.Source) error {
+		return diff(ctx, files, w)
+	})
+}
+
+// diffRelation returns the relation of the key whose left is the source 1.
+func diffRelation(jKey *joinkey.JoinKey) (*joinkey.Relation, error) {
+	if len(jKey.RelationList) != 1 {
+		return nil, fmt.Errorf("%w: want a relation, got %s", errDiffKey, jKey.Expr())
+	}
+	rel := jKey.RelationList[0]
+	switch {
+	case rel.Left.Src == 1 && rel.Right.Src == 2:
+		return rel, nil
+	case rel.Left.Src == 2 && rel.Right.Src == 1:
+		return joinkey.NewRelation(rel.Right, rel.Left), nil
+	default:
+		return nil, fmt.Errorf("%w: want a relation between the source 1 and 2, got %s", errDiffKey, jKey.Expr())
+	}
+}
+
+func diff(ctx context.Context, fs []source.Source, w diffWriter) error {
+	jKey, err := parseKey(len(fs))
+	if err != nil {
+		return err
+	}
+	rel, err := diffRelation(jKey)
+	if err != nil {
+		return err
+	}
+	locs := joiner.RelationListToLocationList([]*joinkey.Relation{rel})
+	cache, err := buildCache(ctx, fs, locs)
+	if err != nil {
+		return err
+	}
+	defer logReadStats(cache, len(fs))
+	left, _ := cache.Get(locs[0].Source(), locs[0].Column())
+	right, _ := cache.Get(locs[1].Source(), locs[1].Column())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for result := range joiner.NewDiffer(left, right).Diff(ctx) {
+		if err := result.Err(); err != nil {
+			return err
+		}
+		if err := w.write(result); err != nil {
+			return fmt.Errorf("write diff: %w", err)
+		}
+	}
+	return nil
+}
+
+type diffWriter interface {
+	write(result joiner.DiffResult) error
+}
+
+var errUnknownFormat = errors.New("UnknownFormat")
+
+func newDiffWriter(format string, w io.Writer) (diffWriter, error) {
+	switch format {
+	case "delimited":
+		return &delimitedDiffWriter{
+			w: w,
+		}, nil
+	case "json":
+		return &jsonDiffWriter{
+			enc: json.NewEncoder(w),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+}
+
+type delimitedDiffWriter struct {
+	w io.Writer
+}
+
+// row writes the row tagged by tag.
+func (d *delimitedDiffWriter) row(tag string, cols []int, row []string) error {
+	ss := make([]string, len(cols))
+	for i, c := range cols {
+		ss[i] = strconv.Itoa(c)
+	}
+	line := append([]string{tag, strings.Join(ss, ";")}, row...)
+	_, err := fmt.Fprintln(d.w, strings.Join(line, *delim))
+	return err
+}
+
+func (d *delimitedDiffWriter) write(result joiner.DiffResult) error {
+	switch result.Kind() {
+	case joiner.DiffOnlyLeft:
+		return d.row(result.Kind().String(), nil, result.Left())
+	case joiner.DiffOnlyRight:
+		return d.row(result.Kind().String(), nil, result.Right())
+	default:
+		// the tags tell the sides apart even if the lines are filtered or sorted
+		if err := d.row(result.Kind().String()+"-left", result.Columns(), result.Left()); err != nil {
+			return err
+		}
+		return d.row(result.Kind().String()+"-right", result.Columns(), result.Right())
+	}
+}
+
+type jsonDiffWriter struct {
+	enc *json.Encoder
+}
+
+type jsonDiff struct {
+	Kind    string   `json:"kind"`
+	Key     string   `json:"key"`
+	Left    []string `json:"left,omitempty"`
+	Right   []string `json:"right,omitempty"`
+	Columns []int    `json:"columns,omitempty"`
+}
+
+func (j *jsonDiffWriter) write(result joiner.DiffResult) error {
+	return j.enc.Encode(&jsonDiff{
+		Kind:    result.Kind().String(),
+		Key:     result.Key(),
+		Left:    result.Left(),
+		Right:   result.Right(),
+		Columns: result.Columns(),
+	})
+}
@@ -0,0 +1,685 @@
+package main_test
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func TestEndToEnd(t *testing.T) {
+	r := newRunner(t)
+	defer r.close()
+
+	const (
+		accounts = `1,account1,HR
+2,account2,Dev
+4,account4,HR
+3,account3,PR
+`
+		departments = `10,HR,Human Resources
+12,PR,Public Relations
+11,Dev,Development
+`
+		department_ext = `Development,2
+Human Resources,2b
+Public Relations,3a
+Marketing,1b
+Accounting,1a
+`
+	)
+
+	var (
+		accountsCSV      = r.path("accounts.csv")
+		departmentsCSV   = r.path("departments.csv")
+		departmentExtCSV = r.path("department_ext.csv")
+	)
+
+	data := map[string]string{
+		accountsCSV:      accounts,
+		departmentsCSV:   departments,
+		departmentExtCSV: department_ext,
+	}
+	for name, content := range data {
+		f, err := os.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := io.WriteString(f, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		title string
+		args  []string
+		stdin io.Reader
+		want  []string
+	}{
+		{
+			title: "join accounts no changes",
+			args:  []string{accountsCSV},
+			want:  strings.Split(strings.TrimRight(accounts, "\n"), "\n"),
+		},
+		{
+			title: "join accounts from stdin no changes",
+			args:  []string{"-x"},
+			stdin: bytes.NewBufferString(accounts),
+			want:  strings.Split(strings.TrimRight(accounts, "\n"), "\n"),
+		},
+		{
+			title: "join accounts from stdin no changes with target",
+			args:  []string{"-x", "-t", "1.2"},
+			stdin: bytes.NewBufferString(accounts),
+			want: []string{
+				"account1",
+				"account2",
+				"account3",
+				"account4",
+			},
+		},
+		{
+			title: "join accounts and department",
+			args:  []string{"-k", "1.3=2.2", accountsCSV, departmentsCSV},
+			want: []string{
+				"1,account1,HR,10,HR,Human Resources",
+				"2,account2,Dev,11,Dev,Development",
+				"3,account3,PR,12,PR,Public Relations",
+				"4,account4,HR,10,HR,Human Resources",
+			},
+		},
+		{
+			title: "join accounts and department with target",
+			args:  []string{"-k", "1.3=2.2", "-t", "-1.2,2.3", accountsCSV, departmentsCSV},
+			want: []string{
+				"1,account1,Human Resources",
+				"2,account2,Development",
+				"3,account3,Public Relations",
+				"4,account4,Human Resources",
+			},
+		},
+		{
+			title: "join accounts from stdin and department with target",
+			args:  []string{"-x", "-k", "1.3=2.2", "-t", "2.1,1.1,2.3", departmentsCSV},
+			stdin: bytes.NewBufferString(accounts),
+			want: []string{
+				"10,1,Human Resources",
+				"11,2,Development",
+				"10,4,Human Resources",
+				"12,3,Public Relations",
+			},
+		},
+		{
+			title: "join accounts, departments and department_ext",
+			args:  []string{"-k", "1.3=2.2,2.3=3.1", accountsCSV, departmentsCSV, departmentExtCSV},
+			want: []string{
+				"1,account1,HR,10,HR,Human Resources,Human Resources,2b",
+				"2,account2,Dev,11,Dev,Development,Development,2",
+				"3,account3,PR,12,PR,Public Relations,Public Relations,3a",
+				"4,account4,HR,10,HR,Human Resources,Human Resources,2b",
+			},
+		},
+		{
+			title: "join accounts from stdin, departments and department_ext with target",
+			args:  []string{"-x", "-k", "1.3=2.2,2.3=3.1", "-t", "-1.2,-2.2,3.1-", departmentsCSV, departmentExtCSV},
+			stdin: bytes.NewBufferString(accounts),
+			want: []string{
+				"1,account1,10,HR,Human Resources,2b",
+				"2,account2,11,Dev,Development,2",
+				"3,account3,12,PR,Public Relations,3a",
+				"4,account4,10,HR,Human Resources,2b",
+			},
+		},
+		{
+			title: "join with unordered key",
+			args:  []string{"-reorder=false", "-k", "1.3=2.2,3.1=4.3,2.3=3.1", "-t", "1.1,4.1,3.2", accountsCSV, departmentsCSV, departmentExtCSV, departmentsCSV},
+			want: []string{
+				"1,10,2b",
+				"2,11,2",
+				"3,12,3a",
+				"4,10,2b",
+			},
+		},
+		{
+			title: "sql join accounts and department",
+			args: []string{"sql", fmt.Sprintf(
+				"SELECT a.1, a.2, d.3 FROM %s a JOIN %s d ON a.3 = d.2",
+				accountsCSV, departmentsCSV,
+			)},
+			want: []string{
+				"1,account1,Human Resources",
+				"2,account2,Development",
+				"3,account3,Public Relations",
+				"4,account4,Human Resources",
+			},
+		},
+		{
+			title: "sql join accounts from stdin, departments and department_ext with where",
+			args: []string{"sql", fmt.Sprintf(
+				"SELECT a.*, x.2 FROM - a JOIN %s d ON a.3 = d.2 JOIN %s x ON d.3 = x.1 WHERE x.2 <> '2b'",
+				departmentsCSV, departmentExtCSV,
+			)},
+			stdin: bytes.NewBufferString(accounts),
+			want: []string{
+				"2,account2,Dev,2",
+				"3,account3,PR,3a",
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var got bytes.Buffer
+			cmd := newCommand(r.runnable, tc.args...).setStdout(&got)
+			if tc.stdin != nil {
+				cmd.setStdin(tc.stdin)
+			}
+			if err := cmd.run(); err != nil {
+				t.Fatalf("%v", err)
+			}
+			ss := strings.Split(strings.TrimRight(got.String(), "\n"), "\n")
+			sort.Strings(ss)
+			sort.Strings(tc.want)
+			assert.Equal(t, tc.want, ss)
+		})
+	}
+
+	for _, tc := range []struct {
+		title string
+		args  []string
+	}{
+		{
+			title: "mmap",
+			args:  []string{"-mmap"},
+		},
+		{
+			title: "chunks",
+			args:  []string{"-chunk-threads", "3", "-chunk-size", "1"},
+		},
+		{
+			title: "map layout",
+			args:  []string{"-index-layout", "map"},
+		},
+		{
+			title: "unordered selection",
+			args:  []string{"-select-threads", "4", "-ordered=false"},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			var got bytes.Buffer
+			args := append(tc.args, "-k", "1.3=2.2", "-t", "1.1,2.3", accountsCSV, departmentsCSV)
+			if err := newCommand(r.runnable, args...).setStdout(&got).run(); err != nil {
+				t.Fatal(err)
+			}
+			ss := strings.Split(strings.TrimRight(got.String(), "\n"), "\n")
+			sort.Strings(ss)
+			assert.Equal(t, []string{"1,Human Resources", "2,Development", "3,Public Relations", "4,Human Resources"}, ss)
+		})
+	}
+
+	t.Run("ordered selection", func(t *testing.T) {
+		join := func(threads string) string {
+			var got bytes.Buffer
+			if err := newCommand(r.runnable, "-select-threads", threads, "-k", "1.3=2.2", "-t", "1.1,2.3", accountsCSV, departmentsCSV).setStdout(&got).run(); err != nil {
+				t.Fatal(err)
+			}
+			return got.String()
+		}
+		assert.Equal(t, join("1"), join("4"))
+	})
+
+	t.Run("progress summary", func(t *testing.T) {
+		var (
+			stderr    bytes.Buffer
+			unmatched = r.path("progress_unmatched.csv")
+		)
+		if err := newCommand(r.runnable, "-progress", "json", "-k", "1.3=2.1", "-unmatched", "2="+unmatched, departmentsCSV, departmentExtCSV).
+			setStderr(&stderr).setStdout(io.Discard).run(); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimRight(stderr.String(), "\n"), "\n")
+		var got struct {
+			Summary struct {
+				Sources []struct {
+					Source    int  `json:"source"`
+					Rows      int  `json:"rows"`
+					Unmatched *int `json:"unmatched"`
+				} `json:"sources"`
+				Rows   int `json:"rows"`
+				Phases []struct {
+					Phase string `json:"phase"`
+				} `json:"phases"`
+			} `json:"summary"`
+		}
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &got); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 3, got.Summary.Rows)
+		if assert.Len(t, got.Summary.Sources, 2) {
+			assert.Equal(t, 3, got.Summary.Sources[0].Rows)
+			assert.Nil(t, got.Summary.Sources[0].Unmatched)
+			assert.Equal(t, 5, got.Summary.Sources[1].Rows)
+			if assert.NotNil(t, got.Summary.Sources[1].Unmatched) {
+				assert.Equal(t, 2, *got.Summary.Sources[1].Unmatched)
+			}
+		}
+		phases := make([]string, len(got.Summary.Phases))
+		for i, p := range got.Summary.Phases {
+			phases[i] = p.Phase
+		}
+		assert.Equal(t, []string{"index", "join", "unmatched"}, phases)
+	})
+
+	t.Run("profiles on interrupt", func(t *testing.T) {
+		var (
+			left  = r.path("interrupt_left.csv")
+			right = r.path("interrupt_right.csv")
+			cpu   = r.path("interrupt_cpu.pprof")
+			tr    = r.path("interrupt_trace.out")
+			lb    bytes.Buffer
+			rb    bytes.Buffer
+		)
+		// the rows are much more than the pipe buffer so that the join blocks until stdout is read
+		for i := range 200000 {
+			fmt.Fprintf(&lb, "%d,%d\n", i, i%1000)
+		}
+		for i := range 1000 {
+			fmt.Fprintf(&rb, "%d,v%d\n", i, i)
+		}
+		if err := os.WriteFile(left, lb.Bytes(), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(right, rb.Bytes(), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cmd := newCommand(r.runnable, "-cpuprofile", cpu, "-trace", tr, "-k", "1.2=2.1", left, right).setStderr(io.Discard)
+		stdout, err := cmd.cmd.StdoutPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		// the first row means that the join is running
+		if _, err := bufio.NewReader(stdout).ReadString('\n'); err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.cmd.Process.Signal(os.Interrupt); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, stdout)
+		assert.NotNil(t, cmd.cmd.Wait(), "interrupted")
+		for _, p := range []string{cpu, tr} {
+			info, err := os.Stat(p)
+			if assert.Nil(t, err, p) {
+				assert.Greater(t, info.Size(), int64(0), p)
+			}
+		}
+	})
+
+	t.Run("profiles", func(t *testing.T) {
+		var (
+			cpu    = r.path("cpu.pprof")
+			mem    = r.path("mem.pprof")
+			tr     = r.path("trace.out")
+			report = r.path("bench.json")
+		)
+		if err := newCommand(r.runnable, "-cpuprofile", cpu, "-memprofile", mem, "-trace", tr, "-bench-report", report,
+			"-k", "1.3=2.2", accountsCSV, departmentsCSV).setStdout(io.Discard).run(); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range []string{cpu, mem, tr} {
+			info, err := os.Stat(p)
+			if assert.Nil(t, err, p) {
+				assert.Greater(t, info.Size(), int64(0), p)
+			}
+		}
+		b, err := os.ReadFile(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Phases []struct {
+				Phase  string `json:"phase"`
+				Source int    `json:"source"`
+			} `json:"phases"`
+		}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		phases := map[string]int{}
+		for _, p := range got.Phases {
+			phases[fmt.Sprintf("%s %d", p.Phase, p.Source)]++
+		}
+		for _, p := range []string{"load 1", "load 2", "load.read 1", "build 0"} {
+			assert.Equal(t, 1, phases[p], p)
+		}
+	})
+
+	t.Run("unknown progress format", func(t *testing.T) {
+		assert.NotNil(t, newCommand(r.runnable, "-progress", "xml", accountsCSV, departmentsCSV).run())
+	})
+
+	t.Run("malformed lines as empty", func(t *testing.T) {
+		var (
+			first  = r.path("malformed_1.csv")
+			second = r.path("malformed_2.csv")
+			third  = r.path("malformed_3.csv")
+		)
+		for name, content := range map[string]string{
+			first:  "a,1\nb,2\n",
+			second: "a,1,x\nb,2\n",
+			third:  "x,X\n,EMPTY\n",
+		} {
+			if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// the second line of the second source has no 2.3, it is joined and selected as empty
+		var got bytes.Buffer
+		if err := newCommand(r.runnable, "-malformed", "empty", "-k", "1.1=2.1,2.3=3.1", first, second, third).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		ss := strings.Split(strings.TrimRight(got.String(), "\n"), "\n")
+		sort.Strings(ss)
+		assert.Equal(t, []string{"a,1,a,1,x,x,X", "b,2,b,2,,,EMPTY"}, ss)
+	})
+
+	t.Run("malformed key", func(t *testing.T) {
+		var stderr bytes.Buffer
+		assert.NotNil(t, newCommand(r.runnable, "-k", "1.3=2,2", accountsCSV, departmentsCSV).setStderr(&stderr).run())
+		assert.Contains(t, stderr.String(), `invalid key: Syntax: unexpected ',', expected '.'
+  1.3=2,2
+       ^
+  did you mean 1.3=2.2?
+`)
+	})
+
+	t.Run("zero column of target", func(t *testing.T) {
+		var stderr bytes.Buffer
+		assert.NotNil(t, newCommand(r.runnable, "-t", "1.0", accountsCSV, departmentsCSV).setStderr(&stderr).run())
+		assert.Contains(t, stderr.String(), "InvalidNumber: column should be 1 or greater")
+	})
+
+	t.Run("unreferenced source", func(t *testing.T) {
+		assert.NotNil(t, newCommand(r.runnable, "-k", "1.3=2.2", accountsCSV, departmentsCSV, departmentExtCSV).run())
+	})
+
+	t.Run("unmatched rows", func(t *testing.T) {
+		var (
+			got       bytes.Buffer
+			unmatched = r.path("unmatched.csv")
+		)
+		if err := newCommand(r.runnable, "-k", "1.3=2.1", "-unmatched", "2="+unmatched, departmentsCSV, departmentExtCSV).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 3, strings.Count(got.String(), "\n"))
+		b, err := os.ReadFile(unmatched)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Marketing,1b\nAccounting,1a\n", string(b))
+	})
+
+	t.Run("sql unmatched rows with where", func(t *testing.T) {
+		var (
+			got       bytes.Buffer
+			unmatched = r.path("sql_unmatched.csv")
+			q         = fmt.Sprintf("SELECT d.1 FROM %s d JOIN %s x ON d.3 = x.1 WHERE d.1 > 10", departmentsCSV, departmentExtCSV)
+		)
+		if err := newCommand(r.runnable, "sql", "-unmatched", "2="+unmatched, q).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 2, strings.Count(got.String(), "\n"))
+		b, err := os.ReadFile(unmatched)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Human Resources,2b\nMarketing,1b\nAccounting,1a\n", string(b))
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		var got bytes.Buffer
+		if err := newCommand(r.runnable, "diff", "-k", "1.2=2.2", departmentsCSV, departmentExtCSV).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		ss := strings.Split(strings.TrimRight(got.String(), "\n"), "\n")
+		assert.Equal(t, 8, len(ss))
+		assert.Equal(t, "only-left,,10,HR,Human Resources", ss[0])
+		assert.Equal(t, "only-right,,Accounting,1a", ss[7])
+	})
+
+	t.Run("diff changed", func(t *testing.T) {
+		var (
+			got       bytes.Buffer
+			yesterday = r.path("yesterday.csv")
+			today     = r.path("today.csv")
+		)
+		for name, content := range map[string]string{
+			yesterday: "1,account1,HR\n2,account2,Dev\n3,account3,PR\n",
+			today:     "1,account1,HR\n2,account2,PR\n4,account4,HR\n",
+		} {
+			if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := newCommand(r.runnable, "diff", yesterday, today).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `changed-left,3,2,account2,Dev
+changed-right,3,2,account2,PR
+only-left,,3,account3,PR
+only-right,,4,account4,HR
+`, got.String())
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		var got bytes.Buffer
+		if err := newCommand(r.runnable, "stats", "-o", "json", "-n", "1", "-k", "1.3=2.2", accountsCSV, departmentsCSV).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `{"columns":[{"location":"1.3","rows":4,"distinct":3,"empty":0,"max_fanout":2,"top":[{"key":"HR","count":2}]},`+
+			`{"location":"2.2","rows":3,"distinct":3,"empty":0,"max_fanout":1,"top":[{"key":"Dev","count":1}]}],`+
+			`"relations":[{"relation":"1.3=2.2","join_rows":4}]}`+"\n", got.String())
+	})
+
+	t.Run("duplicate policy drops lines from all columns", func(t *testing.T) {
+		var (
+			left   = r.path("dup_left.csv")
+			middle = r.path("dup_middle.csv")
+			right  = r.path("dup_right.csv")
+		)
+		for name, content := range map[string]string{
+			left:   "a,l\n",
+			middle: "a,x\na,y\n",
+			right:  "x,rx\ny,ry\n",
+		} {
+			if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// the chain starts from 2.2 which is not unique
+		for _, tc := range []struct {
+			dup  string
+			want string
+		}{
+			{dup: "first", want: "a,l,a,x,x,rx\n"},
+			{dup: "last", want: "a,l,a,y,y,ry\n"},
+		} {
+			t.Run(tc.dup, func(t *testing.T) {
+				var got bytes.Buffer
+				if err := newCommand(r.runnable, "-reorder=false", "-cardinality", "n:n,n:1", "-dup", tc.dup,
+					"-k", "2.2=3.1,1.1=2.1", left, middle, right).setStdout(&got).run(); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, tc.want, got.String())
+			})
+		}
+	})
+
+	t.Run("jsonl source", func(t *testing.T) {
+		jsonl := r.path("departments.jsonl")
+		if err := os.WriteFile(jsonl, []byte(`{"id":10,"code":"HR"}
+{"id":11,"code":"Dev"}
+`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		var got bytes.Buffer
+		if err := newCommand(r.runnable, "-k", "1.3=2.2", "-t", "1.2,2.1", accountsCSV, "jsonl:"+jsonl).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		ss := strings.Split(strings.TrimRight(got.String(), "\n"), "\n")
+		sort.Strings(ss)
+		assert.Equal(t, []string{"account1,10", "account2,11", "account4,10"}, ss)
+	})
+
+	t.Run("sqlite source", func(t *testing.T) {
+		dbPath := r.path("departments.db")
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, q := range []string{
+			`CREATE TABLE departments (id INTEGER, code TEXT, name TEXT)`,
+			`INSERT INTO departments VALUES (10, 'HR', 'Human Resources'), (12, 'PR', 'Public Relations'), (11, 'Dev', 'Development')`,
+		} {
+			if _, err := db.Exec(q); err != nil {
+				t.Fatal(err)
+			}
+		}
+		db.Close()
+
+		var got bytes.Buffer
+		if err := newCommand(r.runnable, "-k", "1.3=2.2", "-t", "1.1,2.3", accountsCSV, "sqlite:"+dbPath+":departments").setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		ss := strings.Split(strings.TrimRight(got.String(), "\n"), "\n")
+		sort.Strings(ss)
+		assert.Equal(t, []string{"1,Human Resources", "2,Development", "3,Public Relations", "4,Human Resources"}, ss)
+
+		t.Run("explain", func(t *testing.T) {
+			assert.NotNil(t, newCommand(r.runnable, "-explain", "-k", "1.3=2.2", accountsCSV, "sqlite:"+dbPath+":departments").run())
+		})
+	})
+
+	t.Run("sql order by and limit", func(t *testing.T) {
+		var got bytes.Buffer
+		q := fmt.Sprintf("SELECT a.2 FROM %s a ORDER BY a.1 DESC LIMIT 3", accountsCSV)
+		if err := newCommand(r.runnable, "sql", q).setStdout(&got).run(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "account4\naccount3\naccount2\n", got.String())
+	})
+
+	t.Run("sql left join", func(t *testing.T) {
+		accountsPath := r.path("left_accounts.csv")
+		if err := os.WriteFile(accountsPath, []byte(accounts+"5,account5,QA\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			title string
+			query string
+			want  string
+		}{
+			{
+				title: "left join",
+				query: "SELECT a.1, d.* FROM %[1]s a LEFT JOIN %[2]s d ON a.3 = d.2 ORDER BY a.1",
+				want:  "1,10,HR,Human Resources\n2,11,Dev,Development\n3,12,PR,Public Relations\n4,10,HR,Human Resources\n5,,,\n",
+			},
+			{
+				title: "left join then join",
+				query: "SELECT a.1, x.2 FROM %[1]s a LEFT JOIN %[2]s d ON d.2 = a.3 JOIN %[3]s x ON d.3 = x.1 ORDER BY a.1",
+				want:  "1,2b\n2,2\n3,3a\n4,2b\n",
+			},
+			{
+				title: "left join with where",
+				query: "SELECT a.1, d.1 FROM %[1]s a LEFT OUTER JOIN %[2]s d ON a.3 = d.2 WHERE a.1 > 3 ORDER BY a.1",
+				want:  "4,10\n5,\n",
+			},
+		} {
+			t.Run(tc.title, func(t *testing.T) {
+				var got bytes.Buffer
+				q := fmt.Sprintf(tc.query, accountsPath, departmentsCSV, departmentExtCSV)
+				if err := newCommand(r.runnable, "sql", q).setStdout(&got).run(); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, tc.want, got.String())
+			})
+		}
+	})
+
+	t.Run("sql unsupported", func(t *testing.T) {
+		q := fmt.Sprintf("SELECT * FROM %s a RIGHT JOIN %s d ON a.3 = d.2", accountsCSV, departmentsCSV)
+		assert.NotNil(t, newCommand(r.runnable, "sql", q).run())
+	})
+}
+
+type runner struct {
+	dir      string
+	runnable string
+}
+
+func newRunner(t *testing.T) *runner {
+	t.Helper()
+	r := &runner{}
+	r.init(t)
+	return r
+}
+
+func (r *runner) init(t *testing.T) {
+	t.Helper()
+	// make a directory to install joiny binary and other files for test.
+	dir, err := os.MkdirTemp("", "joiny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.dir = dir
+	r.runnable = r.path("joiny")
+	// build joiny binary
+	if err := newCommand("go", "build", "-o", r.runnable).setStdout(os.Stdout).run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (r *runner) path(name string) string { return filepath.Join(r.dir, name) }
+func (r *runner) close()                  { os.RemoveAll(r.dir) }
+
+type command struct {
+	cmd *exec.Cmd
+}
+
+func (c *command) run() error { return c.cmd.Run() }
+
+func (c *command) setStdin(r io.Reader) *command {
+	c.cmd.Stdin = r
+	return c
+}
+
+func (c *command) setStdout(w io.Writer) *command {
+	c.cmd.Stdout = w
+	return c
+}
+
+func (c *command) setStderr(w io.Writer) *command {
+	c.cmd.Stderr = w
+	return c
+}
+
+func newCommand(name string, arg ...string) *command {
+	c := exec.Command(name, arg...)
+	c.Stderr = os.Stderr
+	return &command{
+		cmd: c,
+	}
+}
@@ -0,0 +1,807 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/berquerant/joiny"
+	"github.com/berquerant/joiny/async"
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/parseerr"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/logx"
+	"github.com/berquerant/joiny/source"
+	"github.com/berquerant/joiny/temporary"
+)
+
+const usage = `Usage: joiny [flags] FILES...
+       joiny sql [flags] QUERY
+       joiny diff [flags] LEFT RIGHT
+       joiny stats [flags] FILES...
+
+Join files.
+
+key is a join condition, like "1.2=2.3", means that join the 2nd column of the source 1 and
+the 3rd column of the source 2.
+files[0] is the source 1, files[1] is the source 2.
+Default key joins by first columns, e.g. "1.1=2.1"
+The key must connect all sources, the relations may be in any order.
+
+target is an output format, like "1.1,2.1-", means that the 1st column of the source 1 and
+the all columns of the source 2.
+Default target is the all columns.
+The syntax is:
+  natural := natural number
+  location := natural "." natural  // source . column
+  single := location
+  left := location "-"  // left limited
+  right := "-" location  // right limited
+  interval := location "-" location  // left and right limited
+  range := interval | right | left | single
+  target := range {"," range}
+
+e.g.
+$ cat > account.csv <<EOS
+1,account1,HR
+2,account2,Dev
+4,account4,HR
+3,account3,PR
+EOS
+$ cat > department.csv <<EOS
+10,HR,Human Resources
+12,PR,Public Relations
+11,Dev,Development
+EOS
+$ joiny -d "," -k "1.3=2.2" -t "1.1-,2.1-" account.csv department.csv
+1,account1,HR,10,HR,Human Resources
+2,account2,Dev,11,Dev,Development
+4,account4,HR,10,HR,Human Resources
+3,account3,PR,12,PR,Public Relations
+$ joiny -d "," -k "1.3=2.2" -t "-1.2,2.3" account.csv department.csv
+1,account1,Human Resources
+2,account2,Development
+4,account4,Human Resources
+3,account3,Public Relations
+$ joiny -d "," -k "1.3=2.2" -t "2.1,1.1,2.3" department.csv < account.csv
+10,1,Human Resources
+11,2,Development
+10,4,Human Resources
+12,3,Public Relations
+$ joiny -k "1.3=2.2,2.3=3.1" account.csv department.csv department_ext.csv
+1,account1,HR,10,HR,Human Resources,Human Resources,2b
+4,account4,HR,10,HR,Human Resources,Human Resources,2b
+2,account2,Dev,11,Dev,Development,Development,2
+3,account3,PR,12,PR,Public Relations,Public Relations,3a
+
+Read stdin when use -x flag, stdin is the source 1.
+Also "-" in FILES means stdin.
+
+FILES can also be the other sources:
+  path                 delimited file
+  jsonl:path           JSON Lines, the columns are the elements of an array or the values of an object
+  sqlite:path:table    table of SQLite database, the columns are the columns of the table
+-explain is available only for the delimited files.
+e.g.
+$ joiny -k "1.3=2.2" -t "1.1,2.3" account.csv sqlite:company.db:department
+
+$ cat > department_ext.csv <<EOS
+Development,2
+Human Resources,2b
+Public Relations,3a
+Marketing,1b
+Accounting,1a
+EOS
+$ joiny -x -d "," -k "1.3=2.2" -t "2.1,1.1,2.3" department.csv < account.csv | joiny -x -d "," -k "1.3=2.1" -t "1.1-,2.2" department_ext.csv
+10,1,Human Resources,2b
+11,2,Development,2
+10,4,Human Resources,2b
+12,3,Public Relations,3a
+$ joiny -x -k '1.3=2.2,2.3=3.1' -t '-1.2,-2.2,3.1-' department.csv department_ext.csv < account.csv
+1,account1,10,HR,Human Resources,2b
+4,account4,10,HR,Human Resources,2b
+2,account2,11,Dev,Development,2
+3,account3,12,PR,Public Relations,3a
+
+Use sql subcommand to join files by SQL, see joiny sql -h.
+Use diff subcommand to compare 2 files by key, see joiny diff -h.
+Use stats subcommand to see the key distribution, see joiny stats -h.
+
+Use -explain flag to see what joiny will do, e.g.
+$ joiny -explain -k "1.3=2.2,2.3=3.1" -t "-1.2,2.3-" account.csv department.csv department_ext.csv
+key: 1.3=2.2,2.3=3.1
+reordered: 2.3=3.1,1.3=2.2
+sources:
+  1: bytes 57, rows 4, columns 3
+    index 1.3: distinct keys 3
+  2: bytes 64, rows 3, columns 3
+    index 2.2: distinct keys 3
+    index 2.3: distinct keys 3
+  3: bytes 80, rows 5, columns 2
+    index 3.1: distinct keys 5
+chain:
+  1: 2.3=3.1 full join, rows ~3
+  2: 1.3=2.2 join from right, rows ~4
+target: 1.1,1.2,2.3
+
+Flags:`
+
+func Usage() {
+	fmt.Fprintln(os.Stderr, usage)
+	flag.PrintDefaults()
+}
+
+var (
+	targetStr      = flag.String("t", "", "target")
+	key            = flag.String("k", "", "key")
+	readStdin      = flag.Bool("x", false, "read stdin")
+	explain        = flag.Bool("explain", false, "print the join plan instead of joining")
+	reorder        = flag.Bool("reorder", true, "reorder the relations by the statistics of the indexes")
+	prefilter      = flag.Bool("prefilter", true, "drop the rows whose keys are not in the other sides of the relations before reading them")
+	cardinality    = flag.String("cardinality", "", "cardinalities of the relations of the key, 1:1, n:1, 1:n or n:n separated by comma")
+	duplicate      = flag.String("dup", "error", "how to handle the duplicated keys against the cardinality: error, first or last")
+	selectThreads  = flag.Int("select-threads", runtime.NumCPU(), "number of threads to select the columns of the rows")
+	progressFormat = flag.String("progress", "", "report the progress and the summary on stderr: human or json")
+	ordered        = flag.Bool("ordered", true, "write the rows in the order of the join, false writes them as soon as they are selected")
+
+	// common flags for all subcommands
+	delim       = new(string)
+	loadThread  = new(int)
+	cacheSize   = new(int)
+	verbose     = new(int)
+	lenient     = new(bool)
+	malformed   = new(string)
+	rejectFile  = new(string)
+	useMmap     = new(bool)
+	chunks      = new(int)
+	chunkSize   = new(int64)
+	layout      = new(string)
+	cpuProfile  = new(string)
+	memProfile  = new(string)
+	traceFile   = new(string)
+	benchReport = new(string)
+	logFormat   = new(string)
+	logFile     = new(string)
+	logLevels   = new(string)
+	unmatched   = make(unmatchedFiles)
+)
+
+func init() {
+	registerCommonFlags(flag.CommandLine)
+	flag.Var(unmatched, "unmatched", unmatchedUsage)
+}
+
+func registerCommonFlags(fs *flag.FlagSet) {
+	fs.StringVar(delim, "d", ",", "delimiter")
+	fs.IntVar(loadThread, "j", 4, "number of threads to load files")
+	fs.IntVar(cacheSize, "c", 16<<20, "max bytes of the read cache of each source")
+	fs.IntVar(verbose, "v", 0, "verbose level, 1 logs debug and 2 logs trace")
+	fs.StringVar(logFormat, "log-format", "json", "format of the logs: json or text")
+	fs.StringVar(logFile, "log-file", "", "append the logs to the file instead of stderr")
+	fs.StringVar(logLevels, "log-level", "", "levels of the components overriding -v, like parser=info,join=trace, components: parser, index, join or select, levels: info, debug or trace")
+	fs.BoolVar(lenient, "lenient", false, "skip the rows that failed to join or select instead of exiting with an error")
+	fs.StringVar(malformed, "malformed", "fail", "how to handle the lines without the key columns: fail, skip or empty")
+	fs.StringVar(rejectFile, "reject-file", "", "write the lines without the key columns to the file")
+	fs.IntVar(chunks, "chunk-threads", runtime.NumCPU(), "number of threads to index a large file")
+	fs.Int64Var(chunkSize, "chunk-size", 64<<20, "min bytes of a part of a file indexed by a thread")
+	fs.StringVar(layout, "index-layout", "compact", "memory layout of the indexes: compact, or map that is faster to look up but uses more memory")
+	fs.BoolVar(useMmap, "mmap", false, "map the files into memory instead of reading them by syscalls, stdin is read as usual")
+	fs.StringVar(cpuProfile, "cpuprofile", "", "write a CPU profile to the file")
+	fs.StringVar(memProfile, "memprofile", "", "write a heap profile at the end to the file")
+	fs.StringVar(traceFile, "trace", "", "write an execution trace to the file")
+	fs.StringVar(benchReport, "bench-report", "", "write the elapsed times of the phases of indexing and the memory statistics as JSON to the file")
+}
+
+const unmatchedUsage = "write the rows of the source N that found no partner to FILE, N=FILE, can be repeated"
+
+// unmatchedFiles is the destinations of the unmatched rows, from source (one-based) to file.
+type unmatchedFiles map[int]string
+
+var errInvalidUnmatched = errors.New("InvalidUnmatched")
+
+func (u unmatchedFiles) String() string {
+	srcs := make([]int, 0, len(u))
+	for src := range u {
+		srcs = append(srcs, src)
+	}
+	sort.Ints(srcs)
+	ss := make([]string, len(srcs))
+	for i, src := range srcs {
+		ss[i] = fmt.Sprintf("%d=%s", src, u[src])
+	}
+	return strings.Join(ss, ",")
+}
+
+func (u unmatchedFiles) Set(v string) error {
+	src, file, ok := strings.Cut(v, "=")
+	if !ok || file == "" {
+		return fmt.Errorf("%w: %q, want N=FILE", errInvalidUnmatched, v)
+	}
+	n, err := strconv.Atoi(src)
+	if err != nil || n < 1 {
+		return fmt.Errorf("%w: %q, source must be a positive integer", errInvalidUnmatched, v)
+	}
+	u[n] = file
+	return nil
+}
+
+// rowErrorHandler decides whether a failure on a row stops the whole process or not.
+type rowErrorHandler struct {
+	lenient bool
+	skipped int
+}
+
+// handle returns the error if not lenient, otherwise logs it and returns nil to skip the row.
+func (h *rowErrorHandler) handle(msg string, err error, attrs ...logx.Attr) error {
+	if !h.lenient {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	h.skipped++
+	logx.G().Warn(msg, append(attrs, logx.Err(err))...)
+	return nil
+}
+
+func (h *rowErrorHandler) summary() {
+	if h.skipped > 0 {
+		logx.G().Warn("Skipped rows", logx.I("count", h.skipped))
+	}
+}
+
+// subcommands are the modes other than the plain join.
+// A subcommand parses the arguments after its name by itself.
+var subcommands = map[string]func(ctx context.Context, args []string) error{
+	"sql":   runSQL,
+	"diff":  runDiff,
+	"stats": runStats,
+}
+
+func parseCommand() func(context.Context) error {
+	if len(os.Args) > 1 {
+		if sub, ok := subcommands[os.Args[1]]; ok {
+			args := os.Args[2:]
+			return func(ctx context.Context) error {
+				return sub(ctx, args)
+			}
+		}
+	}
+
+	flag.Usage = Usage
+	flag.Parse()
+	list := flag.Args()
+	if *readStdin {
+		list = append([]string{stdinPath}, list...)
+	}
+	return func(ctx context.Context) error {
+		if err := setupLog(); err != nil {
+			return err
+		}
+		return withFileList(ctx, list, run)
+	}
+}
+
+// logCloser closes the file of -log-file.
+var logCloser io.Closer = io.NopCloser(nil)
+
+// setupLog configures the loggers by -v and the log flags.
+func setupLog() error {
+	format, err := logx.ParseFormat(*logFormat)
+	if err != nil {
+		return err
+	}
+	levels, err := logx.ParseComponentLevels(*logLevels)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stderr
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("log file: %w", err)
+		}
+		w = f
+		logCloser = f
+	}
+	logx.Setup(w, format)
+
+	switch {
+	case *verbose >= 2:
+		logx.G().SetLevel(logx.Ltrace)
+	case *verbose == 1:
+		logx.G().SetLevel(logx.Ldebug)
+	default:
+		logx.G().SetLevel(logx.Linfo)
+	}
+	for c, level := range levels {
+		logx.C(c).SetLevel(level)
+	}
+	return nil
+}
+
+func main() {
+	command := parseCommand()
+
+	exitCode := func() int {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		var (
+			doneC = make(chan struct{})
+			err   error
+		)
+		go func() {
+			defer close(doneC)
+			if err = command(ctx); err != nil {
+				var parseErr *parseerr.Error
+				if errors.As(err, &parseErr) {
+					fmt.Fprintln(os.Stderr, parseErr.Detail())
+				}
+				logx.G().Error("got error", logx.Err(err))
+			}
+		}()
+
+		var interrupted bool
+		select {
+		case <-ctx.Done():
+			stop()
+			interrupted = true
+			// the command flushes the outputs and the profiles as it stops
+			select {
+			case <-doneC:
+			case <-time.After(shutdownTimeout):
+				logx.G().Warn("Command did not stop", logx.D("timeout", shutdownTimeout))
+			}
+		case <-doneC:
+		}
+
+		_ = logCloser.Close()
+		if interrupted || err != nil {
+			return 1
+		}
+		return 0
+	}()
+	os.Exit(exitCode)
+}
+
+// shutdownTimeout is the time to wait for the command to stop after the interrupt.
+const shutdownTimeout = 5 * time.Second
+
+var (
+	errNoSources          = errors.New("NoSources")
+	errStdinTwice         = errors.New("StdinTwice")
+	errExplainUnsupported = errors.New("ExplainUnsupported")
+)
+
+func run(ctx context.Context, fs []source.Source) error {
+	if len(fs) < 1 {
+		return errNoSources
+	}
+	jKey, err := parseKey(len(fs))
+	if err != nil {
+		return err
+	}
+	tgt, err := parseTarget(len(fs))
+	if err != nil {
+		return err
+	}
+	if *explain {
+		return printPlan(fs, jKey, tgt)
+	}
+	if err := validateKey(jKey, len(fs)); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	progress, err := newProgressReporter(*progressFormat, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer progress.summary()
+	progress.enter("index")
+	session, err := startJoin(ctx, fs, jKey, progress, joinPlan{})
+	if err != nil {
+		return err
+	}
+	defer session.logReadStats()
+	progress.enter("join")
+	h := &rowErrorHandler{
+		lenient: *lenient,
+	}
+	defer h.summary()
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for r := range selectRows(ctx, session, tgt) {
+		if r.err != nil {
+			var attrs []logx.Attr
+			if r.row != nil {
+				attrs = append(attrs, logx.Any("row", r.row))
+			}
+			if err := h.handle(r.msg, r.err, attrs...); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(w, r.line); err != nil {
+			return err
+		}
+		progress.row()
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(unmatched) > 0 {
+		progress.enter("unmatched")
+	}
+	return session.writeUnmatched(ctx)
+}
+
+// selectResult is a row selected by selectRows.
+type selectResult struct {
+	row  joiner.SelectItemList
+	line string
+	err  error
+	msg  string // describes the failure
+}
+
+// selectRows selects the joined rows by -select-threads goroutines.
+// The results keep the order of the join if -ordered.
+func selectRows(ctx context.Context, session *joinSession, tgt *target.Target) <-chan *selectResult {
+	return async.Map(ctx, session.rowC, *selectThreads, *ordered, func(result joiner.JoinResult) *selectResult {
+		if err := result.Err(); err != nil {
+			return &selectResult{
+				err: err,
+				msg: "Failed to join",
+			}
+		}
+		row := result.Row()
+		line, err := session.Select(tgt, row.Sorted())
+		if err != nil {
+			return &selectResult{
+				row: row,
+				err: err,
+				msg: "Failed to select",
+			}
+		}
+		return &selectResult{
+			row:  row,
+			line: line,
+		}
+	})
+}
+
+// joinSession is a running join.
+type joinSession struct {
+	joiner.Selector
+	rowC     <-chan joiner.JoinResult
+	cache    joiner.Cache
+	sources  int
+	tracker  joiner.Tracker // nil if no unmatched rows are reported
+	progress *progressReporter
+}
+
+// track marks the items of the row as matched if the unmatched rows are reported.
+func (s *joinSession) track(row joiner.SelectItemList) {
+	if s.tracker != nil {
+		s.tracker.Track(row)
+	}
+}
+
+func (s *joinSession) logReadStats() { logReadStats(s.cache, s.sources) }
+
+// logReadStats logs the hit rates of the read caches of the sources.
+func logReadStats(cache joiner.Cache, n int) {
+	for src := 0; src < n; src++ {
+		stats, ok := cache.ReadStats(src)
+		if !ok {
+			continue
+		}
+		logx.G().Debug("Read cache",
+			logx.I("source", src+1),
+			logx.I("hits", stats.Hits),
+			logx.I("misses", stats.Misses),
+			logx.I("bytes", stats.Bytes),
+			logx.I("entries", stats.Entries),
+		)
+	}
+}
+
+// writeUnmatched writes the unmatched rows to the files.
+// This should be called after all rows are consumed.
+func (s *joinSession) writeUnmatched(ctx context.Context) error {
+	if s.tracker == nil {
+		return nil
+	}
+	for src, file := range unmatched {
+		if err := func() error {
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			n, err := s.tracker.WriteUnmatched(ctx, f, src-1)
+			if err != nil {
+				return err
+			}
+			logx.G().Info("Unmatched rows", logx.I("source", src), logx.S("file", file), logx.I("count", n))
+			s.progress.setUnmatched(src-1, n)
+			return nil
+		}(); err != nil {
+			return fmt.Errorf("unmatched rows of source %d: %w", src, err)
+		}
+	}
+	return nil
+}
+
+// joinPlan is how startJoin joins the sources besides the flags.
+type joinPlan struct {
+	optional   []int // zero-based sources joined like LEFT JOIN, the relations are joined in the order of the key
+	trackLater bool  // the caller tracks the rows by joinSession.track instead of the joiner
+}
+
+// startJoin builds indexes of the sources and starts joining.
+// progress counts the loaded records if not nil.
+func startJoin(ctx context.Context, fs []source.Source, jKey *joinkey.JoinKey, progress *progressReporter, plan joinPlan) (*joinSession, error) {
+	for src := range unmatched {
+		if src > len(fs) {
+			return nil, fmt.Errorf("%w: source %d, only %d sources", errInvalidUnmatched, src, len(fs))
+		}
+	}
+	uniqueKeys, err := parseCardinality(jKey)
+	if err != nil {
+		return nil, err
+	}
+	// each relation should share a source with the preceding relations
+	jKey = jKey.Connected()
+	cache, err := buildCache(ctx, fs, joiner.RelationListToLocationList(jKey.RelationList),
+		append([]joiner.LoadOption{
+			joiner.WithUniqueKeys(uniqueKeys...),
+			// the rows without the partners in the optional sources are kept
+			joiner.WithPrefilter(*prefilter && len(plan.optional) == 0),
+		}, progress.loadOptions()...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if *reorder && len(plan.optional) == 0 {
+		jKey = joiner.Reorder(jKey, cache)
+		logx.G().Debug("Reordered", logx.S("key", jKey.Expr()))
+	}
+	var (
+		session = &joinSession{
+			Selector: joiner.NewSelector(cache),
+			cache:    cache,
+			sources:  len(fs),
+			progress: progress,
+		}
+		join = joiner.New(joiner.NewRelationJoiner(cache, joiner.WithOptionalSources(plan.optional...)))
+	)
+	if len(unmatched) > 0 {
+		session.tracker = joiner.NewTracker(cache)
+		if !plan.trackLater {
+			join = joiner.NewTrackingJoiner(join, session.tracker)
+		}
+	}
+	session.rowC = join.Join(ctx, jKey)
+	return session, nil
+}
+
+// parseCardinality returns the unique columns declared by -cardinality.
+func parseCardinality(jKey *joinkey.JoinKey) ([]joiner.UniqueKey, error) {
+	if *cardinality == "" {
+		return nil, nil
+	}
+	policy, err := joiner.ParseDuplicatePolicy(*duplicate)
+	if err != nil {
+		return nil, err
+	}
+	ss := strings.Split(*cardinality, ",")
+	cards := make([]joiner.Cardinality, len(ss))
+	for i, s := range ss {
+		c, err := joiner.ParseCardinality(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		cards[i] = c
+	}
+	return joiner.UniqueKeys(jKey.RelationList, cards, policy)
+}
+
+// buildCache builds indexes of the locations with the malformed line policy.
+func buildCache(ctx context.Context, fs []source.Source, locationList []joiner.Location, opt ...joiner.LoadOption) (joiner.Cache, error) {
+	policy, err := joiner.ParseMalformedPolicy(*malformed)
+	if err != nil {
+		return nil, err
+	}
+	indexLayout, err := joiner.ParseIndexLayout(*layout)
+	if err != nil {
+		return nil, err
+	}
+	w := io.Discard
+	if *rejectFile != "" {
+		f, err := os.Create(*rejectFile)
+		if err != nil {
+			return nil, fmt.Errorf("reject file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	rejecter := joiner.NewRejecter(w)
+	defer func() {
+		if n := rejecter.Count(); n > 0 {
+			logx.G().Warn("Malformed lines", logx.I("count", n), logx.S("policy", policy.String()))
+		}
+	}()
+
+	return joiner.NewSourceCacheBuilder(
+		fs,
+		locationList,
+		*delim,
+		*loadThread,
+		*cacheSize,
+		append([]joiner.LoadOption{
+			joiner.WithMalformedPolicy(policy),
+			joiner.WithRejecter(rejecter),
+			joiner.WithChunkThreads(*chunks),
+			joiner.WithMinChunkSize(*chunkSize),
+			joiner.WithIndexLayout(indexLayout),
+			joiner.WithTimings(benchTimings),
+		}, opt...)...,
+	).Build(ctx)
+}
+
+// explainSampleSize is the bytes of the head of the sources to estimate the rows.
+const explainSampleSize = 1 << 16
+
+func printPlan(fs []source.Source, jKey *joinkey.JoinKey, tgt *target.Target) error {
+	if err := jKey.Validate(len(fs)); errors.Is(err, joinkey.ErrSourceOutOfRange) {
+		return err // cannot estimate the sources that do not exist
+	}
+	// the plan samples the head of the files
+	files := make([]io.ReadSeeker, len(fs))
+	for i, f := range fs {
+		file, ok := f.(source.File)
+		if !ok {
+			return fmt.Errorf("%w: source %d is not a delimited file", errExplainUnsupported, i+1)
+		}
+		files[i] = file.File()
+	}
+	plan, err := joiner.Explain(files, jKey.Connected(), tgt, *delim, explainSampleSize, *reorder)
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan)
+
+	var problems []string
+	if err := jKey.Validate(len(fs)); err != nil {
+		problems = strings.Split(err.Error(), "\n")
+	}
+	for _, r := range jKey.Redundant() {
+		problems = append(problems, redundantMessage(r))
+	}
+	if len(problems) > 0 {
+		fmt.Println("problems:")
+		for _, p := range problems {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+	return nil
+}
+
+// validateKey checks the key as a graph of the n sources.
+func validateKey(jKey *joinkey.JoinKey, n int) error {
+	if err := jKey.Validate(n); err != nil {
+		return fmt.Errorf("invalid key %s: %w", jKey.Expr(), err)
+	}
+	for _, r := range jKey.Redundant() {
+		logx.G().Warn(redundantMessage(r))
+	}
+	return nil
+}
+
+func redundantMessage(r *joinkey.Relation) string {
+	return fmt.Sprintf("relation %s is redundant because the columns are already equal by the other relations, remove it", r.Expr())
+}
+
+// stdinPath is the path that means stdin.
+const stdinPath = "-"
+
+// withFileList opens the sources of the specs, see source.Open.
+// The profiles of the flags cover the callback.
+func withFileList(ctx context.Context, list []string, callback func(context.Context, []source.Source) error) (err error) {
+	stopProfiles, err := startProfiles()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, stopProfiles())
+	}()
+
+	var (
+		fileList []source.Source
+		add      = func(r source.Source) {
+			fileList = append(fileList, r)
+		}
+		stdinRead bool
+	)
+
+	for _, x := range list {
+		if x == stdinPath {
+			if stdinRead {
+				return errStdinTwice
+			}
+			stdinRead = true
+			stdin, err := stdinToTempFile()
+			if err != nil {
+				return err
+			}
+			defer stdin.Close()
+			add(source.NewDelimited(stdin, *delim))
+			continue
+		}
+		src, closer, err := source.Open(x, *delim, source.WithMmap(*useMmap))
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+		add(src)
+	}
+
+	return callback(ctx, fileList)
+}
+
+func stdinToTempFile() (*temporary.File, error) {
+	f, err := temporary.NewFile()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, os.Stdin); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func parseTarget(n int) (*target.Target, error) {
+	l := target.NewLexer(bytes.NewBufferString(getTarget(n)))
+	l.Debug(*verbose)
+	target.Parse(l)
+	if err := l.Err(); err != nil {
+		return nil, err
+	}
+	return l.Target, nil
+}
+
+func getTarget(n int) string {
+	if *targetStr != "" {
+		return *targetStr
+	}
+	return joiny.DefaultTarget(n)
+}
+
+func parseKey(n int) (*joinkey.JoinKey, error) {
+	l := joinkey.NewLexer(bytes.NewBufferString(getKey(n)))
+	l.Debug(*verbose)
+	joinkey.Parse(l)
+	if err := l.Err(); err != nil {
+		return nil, err
+	}
+	return l.JoinKey, nil
+}
+
+func getKey(n int) string {
+	if *key != "" {
+		return *key
+	}
+	return joiny.DefaultKey(n)
+}
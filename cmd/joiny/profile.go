@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+
+	"github.com/berquerant/joiny/joiner"
+)
+
+// benchTimings collects the timings of building indexes if -bench-report is set.
+var benchTimings *joiner.Timings
+
+// startProfiles starts the profiles of -cpuprofile, -trace, -memprofile and -bench-report.
+// The returned function stops them and writes the rest of them.
+func startProfiles() (func() error, error) {
+	var (
+		stops   []func() error
+		startAt = time.Now()
+		stop    = func() error {
+			var errs []error
+			for i := len(stops) - 1; i >= 0; i-- {
+				errs = append(errs, stops[i]())
+			}
+			return errors.Join(errs...)
+		}
+		fail = func(err error) (func() error, error) {
+			return nil, errors.Join(err, stop())
+		}
+	)
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			return fail(fmt.Errorf("cpuprofile: %w", err))
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fail(fmt.Errorf("cpuprofile: %w", err))
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			return fail(fmt.Errorf("trace: %w", err))
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return fail(fmt.Errorf("trace: %w", err))
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+	if *memProfile != "" {
+		stops = append(stops, writeMemProfile)
+	}
+	if *benchReport != "" {
+		benchTimings = joiner.NewTimings()
+		stops = append(stops, func() error {
+			return writeBenchReport(time.Since(startAt))
+		})
+	}
+	return stop, nil
+}
+
+func writeMemProfile() error {
+	f, err := os.Create(*memProfile)
+	if err != nil {
+		return fmt.Errorf("memprofile: %w", err)
+	}
+	defer f.Close()
+	runtime.GC() // up-to-date statistics
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("memprofile: %w", err)
+	}
+	return nil
+}
+
+type benchPhase struct {
+	Phase   string  `json:"phase"`
+	Source  int     `json:"source,omitempty"` // one-based
+	Elapsed float64 `json:"elapsed"`
+}
+
+type benchMemory struct {
+	HeapAlloc  uint64 `json:"heap_alloc"`
+	TotalAlloc uint64 `json:"total_alloc"`
+	Sys        uint64 `json:"sys"`
+	NumGC      uint32 `json:"num_gc"`
+}
+
+type benchResult struct {
+	Args    []string     `json:"args"`
+	Go      string       `json:"go"`
+	CPUs    int          `json:"cpus"`
+	Elapsed float64      `json:"elapsed"`
+	Phases  []benchPhase `json:"phases"`
+	Memory  benchMemory  `json:"memory"`
+}
+
+// writeBenchReport writes the timings of the phases and the memory statistics as JSON.
+// Elapsed times are in seconds.
+func writeBenchReport(elapsed time.Duration) error {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	r := benchResult{
+		Args:    os.Args[1:],
+		Go:      runtime.Version(),
+		CPUs:    runtime.NumCPU(),
+		Elapsed: elapsed.Seconds(),
+		Phases:  []benchPhase{},
+		Memory: benchMemory{
+			HeapAlloc:  m.HeapAlloc,
+			TotalAlloc: m.TotalAlloc,
+			Sys:        m.Sys,
+			NumGC:      m.NumGC,
+		},
+	}
+	for _, t := range benchTimings.List() {
+		r.Phases = append(r.Phases, benchPhase{
+			Phase:   t.Phase,
+			Source:  t.Source + 1,
+			Elapsed: t.Elapsed.Seconds(),
+		})
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("bench report: %w", err)
+	}
+	if err := os.WriteFile(*benchReport, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("bench report: %w", err)
+	}
+	return nil
+}
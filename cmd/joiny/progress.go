@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/berquerant/joiny/joiner"
+)
+
+var errUnknownProgressFormat = errors.New("UnknownProgressFormat")
+
+// progressInterval is the interval of the progress reports.
+const progressInterval = time.Second
+
+// progressReporter reports the progress of a join periodically and its summary at the end.
+// The methods of nil do nothing.
+type progressReporter struct {
+	json      bool
+	w         io.Writer
+	loads     *joiner.Progress
+	rows      atomic.Int64
+	startAt   time.Time
+	stopC     chan struct{}
+	doneC     chan struct{}
+	mux       sync.Mutex
+	phase     string
+	phaseAt   time.Time
+	phases    []phaseElapsed
+	unmatched map[int]int
+}
+
+type phaseElapsed struct {
+	phase   string
+	elapsed time.Duration
+}
+
+// newProgressReporter returns a reporter that writes to w in the format, human or json.
+// Returns nil if the format is empty.
+func newProgressReporter(format string, w io.Writer) (*progressReporter, error) {
+	switch format {
+	case "":
+		return nil, nil
+	case "human", "json":
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownProgressFormat, format)
+	}
+	now := time.Now()
+	r := &progressReporter{
+		json:      format == "json",
+		w:         w,
+		loads:     joiner.NewProgress(),
+		startAt:   now,
+		stopC:     make(chan struct{}),
+		doneC:     make(chan struct{}),
+		phaseAt:   now,
+		unmatched: make(map[int]int),
+	}
+	go r.loop()
+	return r, nil
+}
+
+func (r *progressReporter) loop() {
+	defer close(r.doneC)
+	var (
+		ticker   = time.NewTicker(progressInterval)
+		lastRows int64
+		lastAt   = time.Now()
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stopC:
+			return
+		case now := <-ticker.C:
+			rows := r.rows.Load()
+			rate := float64(rows-lastRows) / now.Sub(lastAt).Seconds()
+			lastRows, lastAt = rows, now
+			r.report(rows, rate)
+		}
+	}
+}
+
+type loadReport struct {
+	Source int   `json:"source"`
+	Bytes  int64 `json:"bytes"`
+	Total  int64 `json:"total"`
+	Lines  int64 `json:"lines"`
+	Done   bool  `json:"done"`
+}
+
+func (r *progressReporter) loadReports() []loadReport {
+	srcs := r.loads.Sources()
+	xs := make([]loadReport, len(srcs))
+	for i, src := range srcs {
+		p := r.loads.Source(src)
+		xs[i] = loadReport{
+			Source: src + 1,
+			Bytes:  p.Bytes(),
+			Total:  p.Total(),
+			Lines:  p.Lines(),
+			Done:   p.Done(),
+		}
+	}
+	return xs
+}
+
+func (r *progressReporter) report(rows int64, rate float64) {
+	r.mux.Lock()
+	phase := r.phase
+	r.mux.Unlock()
+
+	switch phase {
+	case "index":
+		loads := r.loadReports()
+		if r.json {
+			r.writeJSON(map[string]any{
+				"phase":   phase,
+				"sources": loads,
+			})
+			return
+		}
+		ss := make([]string, len(loads))
+		for i, x := range loads {
+			ss[i] = fmt.Sprintf("source %d %s %d lines", x.Source, formatProgressBytes(x.Bytes, x.Total), x.Lines)
+			if x.Done {
+				ss[i] += " done"
+			}
+		}
+		fmt.Fprintf(r.w, "index: %s\n", strings.Join(ss, ", "))
+	case "join":
+		if r.json {
+			r.writeJSON(map[string]any{
+				"phase":        phase,
+				"rows":         rows,
+				"rows_per_sec": rate,
+			})
+			return
+		}
+		fmt.Fprintf(r.w, "join: %d rows, %.0f rows/s\n", rows, rate)
+	}
+}
+
+func (r *progressReporter) writeJSON(v any) {
+	b, _ := json.Marshal(v)
+	fmt.Fprintf(r.w, "%s\n", b)
+}
+
+// loadOptions returns the options to count the loaded records.
+func (r *progressReporter) loadOptions() []joiner.LoadOption {
+	if r == nil {
+		return nil
+	}
+	return []joiner.LoadOption{joiner.WithProgress(r.loads)}
+}
+
+// enter ends the current phase and starts the phase.
+func (r *progressReporter) enter(phase string) {
+	if r == nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.endPhase()
+	r.phase = phase
+	r.phaseAt = time.Now()
+}
+
+func (r *progressReporter) endPhase() {
+	if r.phase == "" {
+		return
+	}
+	r.phases = append(r.phases, phaseElapsed{
+		phase:   r.phase,
+		elapsed: time.Since(r.phaseAt),
+	})
+	r.phase = ""
+}
+
+// row counts a row written.
+func (r *progressReporter) row() {
+	if r == nil {
+		return
+	}
+	r.rows.Add(1)
+}
+
+// setUnmatched records the number of the unmatched rows of the zero-based source.
+func (r *progressReporter) setUnmatched(src, n int) {
+	if r == nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.unmatched[src] = n
+}
+
+type sourceSummary struct {
+	Source    int   `json:"source"`
+	Rows      int64 `json:"rows"`
+	Unmatched *int  `json:"unmatched,omitempty"`
+}
+
+type phaseSummary struct {
+	Phase   string  `json:"phase"`
+	Elapsed float64 `json:"elapsed"`
+}
+
+type runSummary struct {
+	Sources []sourceSummary `json:"sources"`
+	Rows    int64           `json:"rows"`
+	Phases  []phaseSummary  `json:"phases"`
+	Elapsed float64         `json:"elapsed"`
+}
+
+// summary stops the reports and writes the summary.
+func (r *progressReporter) summary() {
+	if r == nil {
+		return
+	}
+	close(r.stopC)
+	<-r.doneC
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.endPhase()
+	s := runSummary{
+		Rows:    r.rows.Load(),
+		Elapsed: time.Since(r.startAt).Seconds(),
+	}
+	for _, x := range r.loadReports() {
+		summary := sourceSummary{
+			Source: x.Source,
+			Rows:   x.Lines,
+		}
+		if n, ok := r.unmatched[x.Source-1]; ok {
+			summary.Unmatched = &n
+		}
+		s.Sources = append(s.Sources, summary)
+	}
+	for _, p := range r.phases {
+		s.Phases = append(s.Phases, phaseSummary{
+			Phase:   p.phase,
+			Elapsed: p.elapsed.Seconds(),
+		})
+	}
+
+	if r.json {
+		r.writeJSON(map[string]any{
+			"summary": s,
+		})
+		return
+	}
+	var b strings.Builder
+	b.WriteString("summary:\n")
+	for _, x := range s.Sources {
+		fmt.Fprintf(&b, "  source %d: %d rows in", x.Source, x.Rows)
+		if x.Unmatched != nil {
+			fmt.Fprintf(&b, ", %d unmatched", *x.Unmatched)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "  rows out: %d\n", s.Rows)
+	for _, p := range r.phases {
+		fmt.Fprintf(&b, "  %s: %s\n", p.phase, p.elapsed.Round(time.Millisecond))
+	}
+	fmt.Fprintf(&b, "  total: %s\n", time.Since(r.startAt).Round(time.Millisecond))
+	fmt.Fprint(r.w, b.String())
+}
+
+// formatProgressBytes formats the bytes and the total, like "1.5 MiB/3.0 MiB (50%)".
+func formatProgressBytes(n, total int64) string {
+	if total <= 0 {
+		return formatBytes(n)
+	}
+	return fmt.Sprintf("%s/%s (%d%%)", formatBytes(n), formatBytes(total), n*100/total)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	var (
+		div   = int64(unit)
+		units = "KMGTPE"
+		i     int
+	)
+	for x := n / unit; x >= unit && i < len(units)-1; x /= unit {
+		div *= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), units[i])
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/berquerant/joiny/cc/sql"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/logx"
+	"github.com/berquerant/joiny/source"
+)
+
+const sqlUsage = `Usage: joiny sql [flags] QUERY
+
+Join files by SQL.
+
+QUERY is a subset of SELECT statement:
+  SELECT select_list
+  FROM path [[AS] alias]
+  {[INNER | LEFT [OUTER]] JOIN path [[AS] alias] ON condition}
+  [WHERE condition]
+  [ORDER BY column [ASC|DESC] {, column [ASC|DESC]}]
+  [LIMIT natural]
+
+path is a file path, quote it by ' or " if it contains spaces. "-" means stdin.
+alias is the file name without the extension by default.
+column is alias "." natural, like "a.2", the 2nd column of the source a.
+select_list is "*" or the list of column or alias ".*".
+condition is the comparisons combined by AND.
+ON accepts only equalities between columns.
+LEFT JOIN keeps the rows without the partners, the columns of the joined source are empty.
+ON of LEFT JOIN should be an equality between a column of the joined source and a column of a preceding source.
+WHERE accepts equalities between columns as joins, and comparisons between a column and a literal
+by =, <>, !=, <, <=, >, >=. Literals are compared as numbers if both sides are numbers.
+-unmatched writes the lines that appear in no rows satisfying WHERE.
+
+e.g.
+$ joiny sql "SELECT a.1, a.2, d.3 FROM account.csv a JOIN department.csv d ON a.3 = d.2 WHERE d.1 >= 11 ORDER BY a.1 DESC"
+3,account3,Public Relations
+2,account2,Development
+
+Flags:`
+
+var errNoQuery = errors.New("NoQuery")
+
+func runSQL(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("sql", flag.ExitOnError)
+	registerCommonFlags(fs)
+	fs.BoolVar(explain, "explain", false, "print the join plan instead of joining")
+	fs.BoolVar(reorder, "reorder", true, "reorder the relations by the statistics of the indexes")
+	fs.StringVar(progressFormat, "progress", "", "report the progress and the summary on stderr: human or json")
+	fs.BoolVar(prefilter, "prefilter", true, "drop the rows whose keys are not in the other sides of the relations before reading them")
+	fs.Var(unmatched, "unmatched", unmatchedUsage)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, sqlUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errNoQuery
+	}
+	if err := setupLog(); err != nil {
+		return err
+	}
+
+	query, err := parseSQL(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	logx.G().Debug("SQL", logx.Any("query", query))
+	list := make([]string, len(query.Sources))
+	for i, src := range query.Sources {
+		list[i] = src.Path
+	}
+	return withFileList(ctx, list, func(ctx context.Context, files []source.Source) error {
+		return runQuery(ctx, query, files)
+	})
+}
+
+func parseSQL(q string) (*sql.Query, error) {
+	l := sql.NewLexer(bytes.NewBufferString(q))
+	l.Debug(*verbose)
+	sql.Parse(l)
+	if err := l.Err(); err != nil {
+		return nil, err
+	}
+	return sql.Compile(l.Statement)
+}
+
+type queryRow struct {
+	selected []string
+	orders   []string
+}
+
+func runQuery(ctx context.Context, query *sql.Query, fs []source.Source) error {
+	if *explain {
+		if err := printPlan(fs, query.JoinKey, query.Target); err != nil {
+			return err
+		}
+		for _, f := range query.Filters {
+			fmt.Printf("filter: %d.%d %s %s\n", f.Loc.Src, f.Loc.Col, f.Op, f.Value)
+		}
+		for _, o := range query.Orders {
+			fmt.Printf("order: %d.%d desc %t\n", o.Loc.Src, o.Loc.Col, o.Desc)
+		}
+		if query.Limit >= 0 {
+			fmt.Printf("limit: %d\n", query.Limit)
+		}
+		return nil
+	}
+
+	if err := validateKey(query.JoinKey, len(fs)); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	progress, err := newProgressReporter(*progressFormat, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer progress.summary()
+	progress.enter("index")
+	optional := make([]int, len(query.Optional))
+	for i, src := range query.Optional {
+		optional[i] = src - 1
+	}
+	// the rows filtered out by WHERE are unmatched
+	session, err := startJoin(ctx, fs, query.JoinKey, progress, joinPlan{
+		optional:   optional,
+		trackLater: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer session.logReadStats()
+	progress.enter("join")
+
+	var (
+		rows    []*queryRow
+		printed int
+		print   = func(selected []string) bool {
+			if query.Limit >= 0 && printed >= query.Limit {
+				return false
+			}
+			fmt.Println(strings.Join(selected, *delim))
+			printed++
+			progress.row()
+			return true
+		}
+	)
+	h := &rowErrorHandler{
+		lenient: *lenient,
+	}
+	defer h.summary()
+	for result := range session.rowC {
+		if err := result.Err(); err != nil {
+			if err := h.handle("Failed to join", err); err != nil {
+				return err
+			}
+			continue
+		}
+		row := result.Row()
+		sources, err := session.Sources(row.Sorted())
+		if err != nil {
+			if err := h.handle("Failed to select", err, logx.Any("row", row)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !matchFilters(query.Filters, sources) {
+			continue
+		}
+		session.track(row)
+		selected, err := joiner.SelectColumnsByTarget(query.Target, sources)
+		if err != nil {
+			if err := h.handle("Failed to select", err, logx.Any("row", row)); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(query.Orders) == 0 {
+			if !print(selected) {
+				if len(unmatched) > 0 {
+					logx.G().Warn("Unmatched rows are not written because the join stopped at the limit")
+				}
+				return nil
+			}
+			continue
+		}
+		orders := make([]string, len(query.Orders))
+		for i, o := range query.Orders {
+			orders[i] = columnValue(o.Loc, sources)
+		}
+		rows = append(rows, &queryRow{
+			selected: selected,
+			orders:   orders,
+		})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for k, o := range query.Orders {
+			c := sql.Compare(rows[i].orders[k], rows[j].orders[k])
+			if c == 0 {
+				continue
+			}
+			if o.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	for _, row := range rows {
+		if !print(row.selected) {
+			break
+		}
+	}
+	if len(unmatched) > 0 {
+		progress.enter("unmatched")
+	}
+	return session.writeUnmatched(ctx)
+}
+
+func matchFilters(filters []*sql.Filter, sources [][]string) bool {
+	for _, f := range filters {
+		if !f.Match(columnValue(f.Loc, sources)) {
+			return false
+		}
+	}
+	return true
+}
+
+// columnValue returns the value of the column, empty if not found.
+func columnValue(loc *target.Location, sources [][]string) string {
+	v, err := joiner.SelectColumnsByRange(target.NewSingle(loc), sources)
+	if err != nil || len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
+)
+
+const statsUsage = `Usage: joiny stats [flags] FILES...
+
+Print the key distribution of the columns of the key.
+
+For each column of the key: rows, distinct keys, lines of the empty key, max lines of a key (fan-out)
+and the most frequent keys.
+For each relation of the key: the number of the rows of the join between the columns.
+
+e.g.
+$ joiny stats -k "1.3=2.2" account.csv department.csv
+COLUMN  ROWS  DISTINCT  EMPTY  MAX_FANOUT  TOP
+1.3     4     3         0      2           HR:2 Dev:1 PR:1
+2.2     3     3         0      1           Dev:1 HR:1 PR:1
+
+RELATION  JOIN_ROWS
+1.3=2.2   4
+
+Flags:`
+
+func runStats(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	registerCommonFlags(fs)
+	fs.StringVar(key, "k", "", "key")
+	top := fs.Int("n", 10, "number of the most frequent keys")
+	format := fs.String("o", "table", "output format: table or json")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, statsUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return errNoSources
+	}
+	if err := setupLog(); err != nil {
+		return err
+	}
+
+	var write func(io.Writer, *statsReport) error
+	switch *format {
+	case "table":
+		write = writeStatsTable
+	case "json":
+		write = writeStatsJSON
+	default:
+		return fmt.Errorf("%w: %s", errUnknownFormat, *format)
+	}
+	return withFileList(ctx, fs.Args(), func(ctx context.Context, files []source.Source) error {
+		report, err := stats(ctx, files, *top)
+		if err != nil {
+			return err
+		}
+		return write(os.Stdout, report)
+	})
+}
+
+type columnStats struct {
+	Location  string            `json:"location"`
+	Rows      int               `json:"rows"`
+	Distinct  int               `json:"distinct"`
+	Empty     int               `json:"empty"`
+	MaxFanOut int               `json:"max_fanout"`
+	Top       []joiner.KeyCount `json:"top"`
+}
+
+type relationStats struct {
+	Relation string `json:"relation"`
+	JoinRows int    `json:"join_rows"`
+}
+
+type statsReport struct {
+	Columns   []*columnStats   `json:"columns"`
+	Relations []*relationStats `json:"relations"`
+}
+
+func stats(ctx context.Context, fs []source.Source, top int) (*statsReport, error) {
+	jKey, err := parseKey(len(fs))
+	if err != nil {
+		return nil, err
+	}
+	// the columns can be inspected even if the key cannot join the sources
+	if err := jKey.Validate(len(fs)); errors.Is(err, joinkey.ErrSourceOutOfRange) {
+		return nil, fmt.Errorf("invalid key %s: %w", jKey.Expr(), err)
+	}
+	cache, err := buildCache(ctx, fs, joiner.RelationListToLocationList(jKey.RelationList))
+	if err != nil {
+		return nil, err
+	}
+	index := func(loc *joinkey.Location) joiner.Index {
+		loc = loc.Add(-1, -1) // zero-based
+		idx, _ := cache.Get(loc.Src, loc.Col)
+		return idx
+	}
+
+	var (
+		report = &statsReport{}
+		locs   []*joinkey.Location
+		seen   = make(map[string]bool)
+	)
+	for _, rel := range jKey.RelationList {
+		for _, loc := range []*joinkey.Location{rel.Left, rel.Right} {
+			if !seen[loc.Expr()] {
+				seen[loc.Expr()] = true
+				locs = append(locs, loc)
+			}
+		}
+		report.Relations = append(report.Relations, &relationStats{
+			Relation: rel.Expr(),
+			JoinRows: joiner.JoinSize(index(rel.Left), index(rel.Right)),
+		})
+	}
+	sort.Slice(locs, func(i, j int) bool {
+		if locs[i].Src != locs[j].Src {
+			return locs[i].Src < locs[j].Src
+		}
+		return locs[i].Col < locs[j].Col
+	})
+	for _, loc := range locs {
+		r := joiner.NewColumnReport(index(loc), top)
+		report.Columns = append(report.Columns, &columnStats{
+			Location:  loc.Expr(),
+			Rows:      r.Rows,
+			Distinct:  r.Distinct,
+			Empty:     r.Empty,
+			MaxFanOut: r.MaxFanOut,
+			Top:       r.Top,
+		})
+	}
+	return report, ctx.Err()
+}
+
+func writeStatsTable(w io.Writer, report *statsReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLUMN\tROWS\tDISTINCT\tEMPTY\tMAX_FANOUT\tTOP")
+	for _, c := range report.Columns {
+		ss := make([]string, len(c.Top))
+		for i, x := range c.Top {
+			ss[i] = x.Key + ":" + strconv.Itoa(x.Count)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", c.Location, c.Rows, c.Distinct, c.Empty, c.MaxFanOut, strings.Join(ss, " "))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "RELATION\tJOIN_ROWS")
+	for _, r := range report.Relations {
+		fmt.Fprintf(tw, "%s\t%d\n", r.Relation, r.JoinRows)
+	}
+	return tw.Flush()
+}
+
+func writeStatsJSON(w io.Writer, report *statsReport) error {
+	return json.NewEncoder(w).Encode(report)
+}
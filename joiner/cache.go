@@ -5,13 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/berquerant/joiny/async"
 	"github.com/berquerant/joiny/cc/joinkey"
 	"github.com/berquerant/joiny/logx"
 	"github.com/berquerant/joiny/slicing"
+	"github.com/berquerant/joiny/source"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -19,6 +20,12 @@ type Cache interface {
 	Get(src, col int) (Index, bool)
 	GetBySrc(src int) ([]Index, bool)
 	Delimiter() string
+	// ReadStats returns the statistics of the read cache of the source.
+	// Returns false if the source has no cache, e.g. in-memory sources.
+	ReadStats(src int) (async.CacheStats, bool)
+	// Prefilter returns the filter of the rows that cannot be joined, see WithPrefilter.
+	Prefilter() Prefilter
+	Statistics
 }
 
 type cacheKey struct {
@@ -30,9 +37,28 @@ type cache struct {
 	val       map[cacheKey]Index
 	srcIdx    map[int][]Index
 	delimiter string
+	prefilter Prefilter
 }
 
-func (c *cache) Delimiter() string { return c.delimiter }
+func (c *cache) Delimiter() string    { return c.delimiter }
+func (c *cache) Prefilter() Prefilter { return c.prefilter }
+
+func (c *cache) ReadStats(src int) (async.CacheStats, bool) {
+	idxs, found := c.srcIdx[src]
+	if !found {
+		return async.CacheStats{}, false
+	}
+	// the indexes of a source share the reader
+	idx, ok := idxs[0].(*index)
+	if !ok {
+		return async.CacheStats{}, false
+	}
+	r, ok := idx.data.(async.StatsReader)
+	if !ok {
+		return async.CacheStats{}, false
+	}
+	return r.Stats(), true
+}
 
 func (c *cache) Get(src, col int) (Index, bool) {
 	idx, found := c.val[cacheKey{
@@ -70,49 +96,78 @@ func RelationListToLocationList(relationList []*joinkey.Relation) []Location {
 	return r
 }
 
-func NewCacheBuilder(dataList []io.ReadSeeker, locationList []Location, delimiter string, limit, indexCacheSize int) CacheBuilder {
-	lockedDataList := make([]async.ReadSeeker, len(dataList))
+// NewCacheBuilder returns a new CacheBuilder.
+// opt is passed to IndexLoader of each source.
+func NewCacheBuilder(dataList []io.ReadSeeker, locationList []Location, delimiter string, limit, cacheBytes int, opt ...LoadOption) CacheBuilder {
+	sources := make([]source.Source, len(dataList))
 	for i, d := range dataList {
-		lockedDataList[i] = async.NewReadSeeker(d)
+		sources[i] = source.NewDelimited(d, delimiter)
 	}
+	return NewSourceCacheBuilder(sources, locationList, delimiter, limit, cacheBytes, opt...)
+}
+
+// NewSourceCacheBuilder returns a new CacheBuilder of the sources.
+// The delimiter is used to join the selected columns.
+// cacheBytes is the size of the read cache of each source.
+// opt is passed to IndexLoader of each source.
+func NewSourceCacheBuilder(sources []source.Source, locationList []Location, delimiter string, limit, cacheBytes int, opt ...LoadOption) CacheBuilder {
 	return &cacheBuilder{
-		dataList:       lockedDataList,
-		delimiter:      delimiter,
-		locationList:   locationList,
-		limit:          limit,
-		indexCacheSize: indexCacheSize,
+		dataList:     sources,
+		delimiter:    delimiter,
+		locationList: locationList,
+		limit:        limit,
+		cacheBytes:   cacheBytes,
+		loadOptions:  opt,
 	}
 }
 
 type cacheBuilder struct {
-	dataList       []async.ReadSeeker
-	delimiter      string
-	locationList   []Location
-	limit          int
-	indexCacheSize int
+	dataList     []source.Source
+	delimiter    string
+	locationList []Location
+	limit        int
+	cacheBytes   int
+	loadOptions  []LoadOption
 }
 
 var ErrInvalidKey = errors.New("InvalidKey")
 
+// IndexLocations returns the columns to be indexed for each source.
+// Sources and columns are zero-based, columns are sorted and unique.
+func IndexLocations(locationList []Location) map[int][]int {
+	r := make(map[int][]int)
+	for _, loc := range locationList {
+		r[loc.Source()] = append(r[loc.Source()], loc.Column())
+	}
+	for src, cols := range r {
+		cols = slicing.Uniq(cols, func(v int) int { return v })
+		sort.Ints(cols)
+		r[src] = cols
+	}
+	return r
+}
+
 func (c *cacheBuilder) Build(ctx context.Context) (Cache, error) {
-	logx.G().Debug("BuilderBuildCache: start", logx.I("sources", len(c.dataList)), logx.I("locations", len(c.locationList)))
+	logx.C(logx.Index).Debug("BuilderBuildCache: start", logx.I("sources", len(c.dataList)), logx.I("locations", len(c.locationList)))
 	startAt := time.Now()
+	config := newLoadConfig(c.loadOptions...)
+	defer config.timings.since("build", -1, startAt)
 
-	cacheKeyList := make([]cacheKey, len(c.locationList))
-	for i, loc := range c.locationList {
-		cacheKeyList[i] = cacheKey{
-			src: loc.Source(),
-			col: loc.Column(),
-		}
-	}
-	cacheKeyList = slicing.Uniq(cacheKeyList, func(v cacheKey) cacheKey { return v })
-	logx.G().Debug("BuilderBuildCache", logx.I("uniq_locations", len(cacheKeyList)))
+	srcToCols := IndexLocations(c.locationList)
+	logx.C(logx.Index).Debug("BuilderBuildCache", logx.I("index_sources", len(srcToCols)))
+	var cacheKeyCount int
 	defer func() {
-		logx.G().Debug("BuilderBuildCache: end", logx.I("caches", len(cacheKeyList)), logx.D("elapsed", time.Since(startAt)))
+		logx.C(logx.Index).Debug("BuilderBuildCache: end", logx.I("caches", cacheKeyCount), logx.D("elapsed", time.Since(startAt)))
 	}()
-	srcToCacheKeyList := make(map[int][]cacheKey)
-	for _, ck := range cacheKeyList {
-		srcToCacheKeyList[ck.src] = append(srcToCacheKeyList[ck.src], ck)
+	srcToCacheKeyList := make(map[int][]cacheKey, len(srcToCols))
+	for src, cols := range srcToCols {
+		for _, col := range cols {
+			srcToCacheKeyList[src] = append(srcToCacheKeyList[src], cacheKey{
+				src: src,
+				col: col,
+			})
+		}
+		cacheKeyCount += len(cols)
 	}
 
 	type resItem struct {
@@ -121,7 +176,7 @@ func (c *cacheBuilder) Build(ctx context.Context) (Cache, error) {
 	}
 
 	var (
-		resC = make(chan *resItem, len(cacheKeyList))
+		resC = make(chan *resItem, cacheKeyCount)
 		eg   errgroup.Group
 	)
 	eg.SetLimit(c.limit)
@@ -137,13 +192,18 @@ func (c *cacheBuilder) Build(ctx context.Context) (Cache, error) {
 		data := c.dataList[src]
 		keyFuncList := make([]KeyFunc, len(ckList))
 		for i, ck := range ckList {
-			keyFuncList[i] = c.keyFunc(ck.col)
+			keyFuncList[i] = c.keyFunc(data, ck.col)
 		}
 
 		eg.Go(func() error {
-			logx.G().Debug("Build Cache: begin", logx.Any("keys", ckList))
-			indexList, err := NewIndexLoader(data, c.indexCacheSize).Load(ctx, keyFuncList...)
-			logx.G().Debug("Build cache: end", logx.Any("keys", ckList))
+			logx.C(logx.Index).Debug("Build Cache: begin", logx.Any("keys", ckList))
+			cols := make([]int, len(ckList))
+			for i, ck := range ckList {
+				cols[i] = ck.col
+			}
+			opt := append([]LoadOption{WithSource(src), WithColumns(cols)}, c.loadOptions...)
+			indexList, err := NewIndexLoader(data, c.cacheBytes, opt...).Load(ctx, keyFuncList...)
+			logx.C(logx.Index).Debug("Build cache: end", logx.Any("keys", ckList))
 			if err != nil {
 				return fmt.Errorf("Build Cache: %w loc %v", err, ckList)
 			}
@@ -170,18 +230,29 @@ func (c *cacheBuilder) Build(ctx context.Context) (Cache, error) {
 		srcIdx[x.key.src] = append(srcIdx[x.key.src], x.idx)
 		val[x.key] = x.idx
 	}
-	return &cache{
+	r := &cache{
 		val:       val,
 		srcIdx:    srcIdx,
 		delimiter: c.delimiter,
-	}, nil
+		prefilter: passAll{},
+	}
+	// a relation alone drops the rows by Get without reading them
+	if config.prefilter && len(c.locationList) > 2 {
+		prefilterAt := time.Now()
+		r.prefilter = newPrefilter(r, c.locationList)
+		config.timings.since("prefilter", -1, prefilterAt)
+	}
+	return r, nil
 }
 
 var ErrNewKeyFailure = errors.New("NewKeyFailure")
 
-func (c *cacheBuilder) keyFunc(col int) KeyFunc {
+func (c *cacheBuilder) keyFunc(src source.Source, col int) KeyFunc {
 	return func(v string) (string, error) {
-		ss := strings.Split(v, c.delimiter)
+		ss, err := src.Split(v)
+		if err != nil {
+			return "", fmt.Errorf("Build cache: %w col %d line %s %w", ErrNewKeyFailure, col, v, err)
+		}
 		if col >= 0 && col < len(ss) {
 			return ss[col], nil
 		}
@@ -0,0 +1,51 @@
+package joiner_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheReadStats(t *testing.T) {
+	const content = `k1,v1
+k2,v2
+`
+	cache, err := joiner.NewSourceCacheBuilder(
+		[]source.Source{
+			source.NewDelimited(strings.NewReader(content), ","),
+			source.NewRows([][]string{{"k1"}}),
+		},
+		[]joiner.Location{
+			joinkey.NewLocation(0, 0),
+			joinkey.NewLocation(0, 1),
+			joinkey.NewLocation(1, 0),
+		},
+		",", 1, 1024,
+	).Build(context.TODO())
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	byKey, _ := cache.Get(0, 0)
+	byValue, _ := cache.Get(0, 1)
+	items, _ := byKey.Get("k1")
+	_, err = byKey.Read(items[0])
+	assert.Nil(t, err)
+	items, _ = byValue.Get("v1")
+	_, err = byValue.Read(items[0])
+	assert.Nil(t, err)
+
+	stats, ok := cache.ReadStats(0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), stats.Hits, "the indexes of a source share the cache")
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, 6, stats.Bytes)
+
+	_, ok = cache.ReadStats(1)
+	assert.False(t, ok, "in-memory source has no cache")
+}
@@ -0,0 +1,191 @@
+package joiner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+)
+
+// DuplicatePolicy decides how to handle the duplicated keys of the unique columns.
+type DuplicatePolicy int
+
+const (
+	// DuplicateError aborts the loading.
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateFirst keeps the first line of the key.
+	DuplicateFirst
+	// DuplicateLast keeps the last line of the key.
+	DuplicateLast
+)
+
+var duplicatePolicyNames = map[DuplicatePolicy]string{
+	DuplicateError: "error",
+	DuplicateFirst: "first",
+	DuplicateLast:  "last",
+}
+
+func (p DuplicatePolicy) String() string {
+	if s, ok := duplicatePolicyNames[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("DuplicatePolicy(%d)", int(p))
+}
+
+var ErrUnknownDuplicatePolicy = errors.New("UnknownDuplicatePolicy")
+
+// ParseDuplicatePolicy parses error, first or last.
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	for p, name := range duplicatePolicyNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	return DuplicateError, fmt.Errorf("%w %q, want error, first or last", ErrUnknownDuplicatePolicy, s)
+}
+
+// Cardinality is the cardinality of a relation, left to right.
+type Cardinality int
+
+const (
+	ManyToMany Cardinality = iota
+	OneToOne
+	ManyToOne
+	OneToMany
+)
+
+var cardinalityNames = map[Cardinality]string{
+	ManyToMany: "n:n",
+	OneToOne:   "1:1",
+	ManyToOne:  "n:1",
+	OneToMany:  "1:n",
+}
+
+func (c Cardinality) String() string {
+	if s, ok := cardinalityNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Cardinality(%d)", int(c))
+}
+
+// Unique returns true if the keys of the side of the relation should be unique.
+func (c Cardinality) Unique() (left, right bool) {
+	switch c {
+	case OneToOne:
+		return true, true
+	case ManyToOne:
+		return false, true
+	case OneToMany:
+		return true, false
+	default:
+		return false, false
+	}
+}
+
+var (
+	ErrUnknownCardinality  = errors.New("UnknownCardinality")
+	ErrCardinalityMismatch = errors.New("CardinalityMismatch")
+)
+
+// ParseCardinality parses 1:1, n:1, 1:n or n:n.
+func ParseCardinality(s string) (Cardinality, error) {
+	for c, name := range cardinalityNames {
+		if name == s {
+			return c, nil
+		}
+	}
+	return ManyToMany, fmt.Errorf("%w %q, want 1:1, n:1, 1:n or n:n", ErrUnknownCardinality, s)
+}
+
+// UniqueKey requires the keys of the column to be unique.
+type UniqueKey struct {
+	// Source and Column are zero-based.
+	Source int
+	Column int
+	Policy DuplicatePolicy
+}
+
+// UniqueKeys returns the unique columns declared by the cardinalities of the relations.
+// cardinalities are aligned with relationList.
+func UniqueKeys(relationList []*joinkey.Relation, cardinalities []Cardinality, policy DuplicatePolicy) ([]UniqueKey, error) {
+	if len(relationList) != len(cardinalities) {
+		return nil, fmt.Errorf("%w: %d cardinalities for %d relations", ErrCardinalityMismatch, len(cardinalities), len(relationList))
+	}
+	var (
+		r    []UniqueKey
+		seen = make(map[cacheKey]bool)
+		add  = func(loc *joinkey.Location) {
+			loc = loc.Add(-1, -1) // zero-based
+			k := cacheKey{
+				src: loc.Src,
+				col: loc.Col,
+			}
+			if seen[k] {
+				return
+			}
+			seen[k] = true
+			r = append(r, UniqueKey{
+				Source: loc.Src,
+				Column: loc.Col,
+				Policy: policy,
+			})
+		}
+	)
+	for i, rel := range relationList {
+		left, right := cardinalities[i].Unique()
+		if left {
+			add(rel.Left)
+		}
+		if right {
+			add(rel.Right)
+		}
+	}
+	return r, nil
+}
+
+var (
+	ErrDuplicateKey          = errors.New("DuplicateKey")
+	ErrConflictingDuplicates = errors.New("ConflictingDuplicates")
+)
+
+// duplicateReportLimit is the max number of the keys in the report of the duplicated keys.
+const duplicateReportLimit = 10
+
+// DuplicateReport is the duplicated keys of a column.
+type DuplicateReport struct {
+	// Keys is the number of the duplicated keys.
+	Keys int
+	// Top is the duplicated keys and their counts, most frequent first, at most duplicateReportLimit.
+	Top []KeyCount
+}
+
+// KeyCount is a key and the number of the lines of the key.
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// sortKeyCounts sorts the keys, most frequent first.
+func sortKeyCounts(list []KeyCount) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Key < list[j].Key
+	})
+}
+
+func (r DuplicateReport) String() string {
+	ss := make([]string, len(r.Top))
+	for i, x := range r.Top {
+		ss[i] = fmt.Sprintf("%q x%d", x.Key, x.Count)
+	}
+	if r.Keys > len(r.Top) {
+		ss = append(ss, fmt.Sprintf("and %d more", r.Keys-len(r.Top)))
+	}
+	return fmt.Sprintf("%d keys: %s", r.Keys, strings.Join(ss, ", "))
+}
+
+// duplicates returns the report of the duplicated keys.
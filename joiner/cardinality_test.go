@@ -0,0 +1,239 @@
+package joiner_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
+	"github.com/berquerant/joiny/temporary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniqueKeys(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		key   string
+		cards []joiner.Cardinality
+		want  []joiner.UniqueKey
+		err   error
+	}{
+		{
+			title: "n:n",
+			key:   "1.1=2.1",
+			cards: []joiner.Cardinality{joiner.ManyToMany},
+		},
+		{
+			title: "1:1",
+			key:   "1.1=2.2",
+			cards: []joiner.Cardinality{joiner.OneToOne},
+			want: []joiner.UniqueKey{
+				{Source: 0, Column: 0, Policy: joiner.DuplicateFirst},
+				{Source: 1, Column: 1, Policy: joiner.DuplicateFirst},
+			},
+		},
+		{
+			title: "n:1 and 1:n",
+			key:   "1.1=2.2,2.2=3.1",
+			cards: []joiner.Cardinality{joiner.ManyToOne, joiner.OneToMany},
+			want: []joiner.UniqueKey{
+				{Source: 1, Column: 1, Policy: joiner.DuplicateFirst},
+			},
+		},
+		{
+			title: "mismatch",
+			key:   "1.1=2.2,2.2=3.1",
+			cards: []joiner.Cardinality{joiner.ManyToOne},
+			err:   joiner.ErrCardinalityMismatch,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := joiner.UniqueKeys(parseKey(t, tc.key).RelationList, tc.cards, joiner.DuplicateFirst)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestIndexLoaderUniqueKeys(t *testing.T) {
+	const content = `k1 v1
+k2 v2
+k1 v3
+`
+	for _, tc := range []struct {
+		title  string
+		policy joiner.DuplicatePolicy
+		want   []string
+		err    error
+	}{
+		{
+			title:  "error",
+			policy: joiner.DuplicateError,
+			err:    joiner.ErrDuplicateKey,
+		},
+		{
+			title:  "first",
+			policy: joiner.DuplicateFirst,
+			want:   []string{"k1 v1"},
+		},
+		{
+			title:  "last",
+			policy: joiner.DuplicateLast,
+			want:   []string{"k1 v3"},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			f, err := temporary.NewFile()
+			if err != nil {
+				t.Fatalf("create tmp file %v", err)
+			}
+			defer f.Close()
+			if _, err := f.Write([]byte(content)); err != nil {
+				t.Fatalf("write to tmp file %v", err)
+			}
+
+			indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, " "), 10,
+				joiner.WithSource(1),
+				joiner.WithColumns([]int{0}),
+				joiner.WithUniqueKeys(joiner.UniqueKey{Source: 1, Column: 0, Policy: tc.policy}),
+			).Load(context.TODO(), func(val string) (string, error) {
+				return strings.Split(val, " ")[0], nil
+			})
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.ErrorContains(t, err, `"k1" x2`)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			items, _ := indexes[0].Get("k1")
+			got := make([]string, len(items))
+			for i, item := range items {
+				scanned, err := indexes[0].Read(item)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[i] = scanned.Line()
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestIndexLoaderUniqueKeysDropLinesFromAllIndexes(t *testing.T) {
+	f, err := temporary.NewFile()
+	if err != nil {
+		t.Fatalf("create tmp file %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("k1 v1\nk2 v2\nk1 v3\n")); err != nil {
+		t.Fatalf("write to tmp file %v", err)
+	}
+
+	column := func(i int) joiner.KeyFunc {
+		return func(val string) (string, error) {
+			return strings.Split(val, " ")[i], nil
+		}
+	}
+	indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, " "), 10,
+		joiner.WithSource(1),
+		joiner.WithColumns([]int{0, 1}),
+		joiner.WithUniqueKeys(joiner.UniqueKey{Source: 1, Column: 0, Policy: joiner.DuplicateFirst}),
+	).Load(context.TODO(), column(0), column(1))
+	if !assert.Nil(t, err) {
+		return
+	}
+	// the line dropped from the index of the first column is dropped from the second too
+	_, found := indexes[1].Get("v3")
+	assert.False(t, found)
+	_, found = indexes[1].Get("v1")
+	assert.True(t, found)
+	assert.Equal(t, 2, indexes[1].Stats().Rows)
+}
+
+func TestIndexLoaderUniqueKeysOfColumns(t *testing.T) {
+	// the duplicated lines of the columns are different, k2 of the 1st column and v1 of the 2nd column
+	const content = `k1 v1
+k2 v1
+k2 v2
+`
+	column := func(i int) joiner.KeyFunc {
+		return func(val string) (string, error) {
+			return strings.Split(val, " ")[i], nil
+		}
+	}
+	for _, tc := range []struct {
+		title  string
+		first  joiner.DuplicatePolicy
+		second joiner.DuplicatePolicy
+		want   [][]string // lines of the keys of the columns
+		err    error
+	}{
+		{
+			title:  "consistent",
+			first:  joiner.DuplicateLast,
+			second: joiner.DuplicateFirst,
+			want: [][]string{
+				{"k1 v1", "k2 v2"},
+				{"k1 v1", "k2 v2"},
+			},
+		},
+		{
+			// the 1st column keeps k2 v1 that the 2nd column drops
+			title:  "conflict",
+			first:  joiner.DuplicateFirst,
+			second: joiner.DuplicateFirst,
+			err:    joiner.ErrConflictingDuplicates,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			f, err := temporary.NewFile()
+			if err != nil {
+				t.Fatalf("create tmp file %v", err)
+			}
+			defer f.Close()
+			if _, err := f.Write([]byte(content)); err != nil {
+				t.Fatalf("write to tmp file %v", err)
+			}
+
+			indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, " "), 10,
+				joiner.WithSource(1),
+				joiner.WithColumns([]int{0, 1}),
+				joiner.WithUniqueKeys(
+					joiner.UniqueKey{Source: 1, Column: 0, Policy: tc.first},
+					joiner.UniqueKey{Source: 1, Column: 1, Policy: tc.second},
+				),
+			).Load(context.TODO(), column(0), column(1))
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			for i, keys := range [][]string{{"k1", "k2"}, {"v1", "v2"}} {
+				got := []string{}
+				for _, k := range keys {
+					items, found := indexes[i].Get(k)
+					if !assert.True(t, found, "column %d key %s", i+1, k) {
+						continue
+					}
+					for _, item := range items {
+						scanned, err := indexes[i].Read(item)
+						if err != nil {
+							t.Fatal(err)
+						}
+						got = append(got, scanned.Line())
+					}
+				}
+				assert.Equal(t, tc.want[i], got, "column %d", i+1)
+			}
+		})
+	}
+}
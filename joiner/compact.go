@@ -0,0 +1,344 @@
+package joiner
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"strings"
+)
+
+// IndexLayout is the in-memory representation of the index.
+type IndexLayout int
+
+const (
+	// LayoutCompact stores the keys once and the rows as arrays, Get allocates the items.
+	LayoutCompact IndexLayout = iota
+	// LayoutMap stores the items of the keys as they are, Get does not allocate but uses several times more memory.
+	LayoutMap
+)
+
+func (l IndexLayout) String() string {
+	switch l {
+	case LayoutCompact:
+		return "compact"
+	case LayoutMap:
+		return "map"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrUnknownIndexLayout = errors.New("UnknownIndexLayout")
+
+func ParseIndexLayout(s string) (IndexLayout, error) {
+	for _, l := range []IndexLayout{LayoutCompact, LayoutMap} {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownIndexLayout, s)
+}
+
+// itemStore is the storage of the items of an index.
+type itemStore interface {
+	get(key string) ([]Item, bool)
+	has(key string) bool
+	allKeys() iter.Seq[string]
+	all() iter.Seq[Item]
+	stats() IndexStats
+}
+
+var (
+	ErrRowTooLarge = errors.New("RowTooLarge")
+	ErrTooManyRows = errors.New("TooManyRows")
+)
+
+// rowTable accumulates the rows of an index in the order of appearance.
+// The keys are interned, a row is a key id, an offset and a size.
+type rowTable struct {
+	ids     map[string]uint32
+	keys    []string
+	keyIDs  []uint32
+	offsets []int64
+	sizes   []uint32
+}
+
+func newRowTable() *rowTable {
+	return &rowTable{
+		ids: make(map[string]uint32),
+	}
+}
+
+func (t *rowTable) id(key string) uint32 {
+	if id, ok := t.ids[key]; ok {
+		return id
+	}
+	// the key may be a substring of the line, do not retain the line
+	key = strings.Clone(key)
+	id := uint32(len(t.keys))
+	t.ids[key] = id
+	t.keys = append(t.keys, key)
+	return id
+}
+
+func (t *rowTable) add(key string, offset int64, size int) error {
+	if size > math.MaxUint32 {
+		return fmt.Errorf("%w: offset %d size %d", ErrRowTooLarge, offset, size)
+	}
+	if len(t.keyIDs) == math.MaxUint32 {
+		return fmt.Errorf("%w: offset %d", ErrTooManyRows, offset)
+	}
+	t.keyIDs = append(t.keyIDs, t.id(key))
+	t.offsets = append(t.offsets, offset)
+	t.sizes = append(t.sizes, uint32(size))
+	return nil
+}
+
+// merge appends the rows of x.
+func (t *rowTable) merge(x *rowTable) {
+	ids := make([]uint32, len(x.keys))
+	for i, k := range x.keys {
+		ids[i] = t.id(k)
+	}
+	for _, id := range x.keyIDs {
+		t.keyIDs = append(t.keyIDs, ids[id])
+	}
+	t.offsets = append(t.offsets, x.offsets...)
+	t.sizes = append(t.sizes, x.sizes...)
+}
+
+func (t *rowTable) counts() []int {
+	r := make([]int, len(t.keys))
+	for _, id := range t.keyIDs {
+		r[id]++
+	}
+	return r
+}
+
+func (t *rowTable) duplicates() DuplicateReport {
+	var top []KeyCount
+	for id, c := range t.counts() {
+		if c > 1 {
+			top = append(top, KeyCount{
+				Key:   t.keys[id],
+				Count: c,
+			})
+		}
+	}
+	sortKeyCounts(top)
+	r := DuplicateReport{
+		Keys: len(top),
+	}
+	if len(top) > duplicateReportLimit {
+		top = top[:duplicateReportLimit]
+	}
+	r.Top = top
+	return r
+}
+
+// dedup applies the policy to the duplicated keys.
+// The offsets of the rows to drop are added to dead, the rows are not dropped yet
+// because they should be dropped from all the tables of the source at once, see drop.
+func (t *rowTable) dedup(policy DuplicatePolicy, dead map[int64]bool) (DuplicateReport, error) {
+	report := t.duplicates()
+	if report.Keys == 0 {
+		return report, nil
+	}
+
+	keep := make([]int, len(t.keys)) // the row to keep of the key
+	switch policy {
+	case DuplicateFirst:
+		for i := len(t.keyIDs) - 1; i >= 0; i-- {
+			keep[t.keyIDs[i]] = i
+		}
+	case DuplicateLast:
+		for i, id := range t.keyIDs {
+			keep[id] = i
+		}
+	default:
+		return report, fmt.Errorf("%w %s", ErrDuplicateKey, report)
+	}
+
+	for i, id := range t.keyIDs {
+		if keep[id] != i {
+			dead[t.offsets[i]] = true
+		}
+	}
+	return report, nil
+}
+
+// lostKey returns a key whose rows are all dead.
+func (t *rowTable) lostKey(dead map[int64]bool) (string, bool) {
+	alive := make([]bool, len(t.keys))
+	for i, id := range t.keyIDs {
+		if !dead[t.offsets[i]] {
+			alive[id] = true
+		}
+	}
+	for id, ok := range alive {
+		if !ok {
+			return t.keys[id], true
+		}
+	}
+	return "", false
+}
+
+// drop removes the rows of the offsets.
+func (t *rowTable) drop(dead map[int64]bool) {
+	if len(dead) == 0 {
+		return
+	}
+	var n int
+	for i, id := range t.keyIDs {
+		if dead[t.offsets[i]] {
+			continue
+		}
+		t.keyIDs[n] = id
+		t.offsets[n] = t.offsets[i]
+		t.sizes[n] = t.sizes[i]
+		n++
+	}
+	t.keyIDs = t.keyIDs[:n]
+	t.offsets = t.offsets[:n]
+	t.sizes = t.sizes[:n]
+	t.dropEmptyKeys()
+}
+
+// dropEmptyKeys removes the keys without rows.
+func (t *rowTable) dropEmptyKeys() {
+	counts := t.counts()
+	if !slices.Contains(counts, 0) {
+		return
+	}
+	var (
+		ids  = make([]uint32, len(t.keys)) // old id to new id
+		keys = t.keys[:0]
+	)
+	clear(t.ids)
+	for id, c := range counts {
+		if c == 0 {
+			continue
+		}
+		ids[id] = uint32(len(keys))
+		t.ids[t.keys[id]] = ids[id]
+		keys = append(keys, t.keys[id])
+	}
+	t.keys = keys
+	for i, id := range t.keyIDs {
+		t.keyIDs[i] = ids[id]
+	}
+}
+
+func (t *rowTable) store(layout IndexLayout) itemStore {
+	if layout == LayoutMap {
+		return t.itemListMap()
+	}
+	return t.compact()
+}
+
+func (t *rowTable) itemListMap() itemListMap {
+	m := make(itemListMap, len(t.keys))
+	for i, id := range t.keyIDs {
+		k := t.keys[id]
+		m.add(k, NewItem(k, t.offsets[i], int(t.sizes[i])))
+	}
+	return m
+}
+
+// compact sorts the rows by the keys, keeping the order of appearance in a key.
+func (t *rowTable) compact() *compactStore {
+	s := &compactStore{
+		ids:     t.ids,
+		keys:    t.keys,
+		start:   make([]uint32, len(t.keys)+1),
+		offsets: make([]int64, len(t.offsets)),
+		sizes:   make([]uint32, len(t.sizes)),
+	}
+	for id, c := range t.counts() {
+		s.start[id+1] = s.start[id] + uint32(c)
+		s.maxFanOut = max(s.maxFanOut, c)
+	}
+	next := make([]uint32, len(t.keys))
+	copy(next, s.start)
+	for i, id := range t.keyIDs {
+		j := next[id]
+		next[id]++
+		s.offsets[j] = t.offsets[i]
+		s.sizes[j] = t.sizes[i]
+	}
+	return s
+}
+
+// compactStore is an itemStore that holds the keys once and the rows as arrays.
+// The rows of the key id i are [start[i], start[i+1]).
+type compactStore struct {
+	ids       map[string]uint32 // shares the key strings with keys
+	keys      []string
+	start     []uint32
+	offsets   []int64
+	sizes     []uint32
+	maxFanOut int
+}
+
+func (s *compactStore) items(id uint32) []Item {
+	var (
+		lo, hi = s.start[id], s.start[id+1]
+		buf    = make([]item, hi-lo)
+		r      = make([]Item, hi-lo)
+	)
+	for i := range buf {
+		j := lo + uint32(i)
+		buf[i] = item{
+			key:    s.keys[id],
+			offset: s.offsets[j],
+			size:   int(s.sizes[j]),
+		}
+		r[i] = &buf[i]
+	}
+	return r
+}
+
+func (s *compactStore) get(key string) ([]Item, bool) {
+	id, ok := s.ids[key]
+	if !ok {
+		return nil, false
+	}
+	return s.items(id), true
+}
+
+func (s *compactStore) has(key string) bool {
+	_, ok := s.ids[key]
+	return ok
+}
+
+func (s *compactStore) allKeys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, k := range s.keys {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func (s *compactStore) all() iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		for id := range s.keys {
+			for _, x := range s.items(uint32(id)) {
+				if !yield(x) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *compactStore) stats() IndexStats {
+	return IndexStats{
+		Rows:      len(s.offsets),
+		Distinct:  len(s.keys),
+		MaxFanOut: s.maxFanOut,
+	}
+}
@@ -0,0 +1,133 @@
+package joiner_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func firstColumn(val string) (string, error) {
+	return strings.Split(val, ",")[0], nil
+}
+
+func loadLayout(tb testing.TB, content string, layout joiner.IndexLayout, opt ...joiner.LoadOption) joiner.Index {
+	tb.Helper()
+	opt = append(opt, joiner.WithIndexLayout(layout))
+	indexes, err := joiner.NewIndexLoader(source.NewDelimited(strings.NewReader(content), ","), 1024, opt...).
+		Load(context.TODO(), firstColumn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return indexes[0]
+}
+
+func TestIndexLayout(t *testing.T) {
+	const content = `k1,a
+k2,b
+k1,c
+
+k3,d
+k1,e
+`
+	items := func(idx joiner.Index, key string) []string {
+		xs, _ := idx.Get(key)
+		r := make([]string, len(xs))
+		for i, x := range xs {
+			r[i] = fmt.Sprintf("%s %d %d", x.Key(), x.Offset(), x.Size())
+		}
+		return r
+	}
+	all := func(idx joiner.Index) []string {
+		var r []string
+		for x := range idx.Items() {
+			r = append(r, fmt.Sprintf("%s %d %d", x.Key(), x.Offset(), x.Size()))
+		}
+		sort.Strings(r)
+		return r
+	}
+
+	want := loadLayout(t, content, joiner.LayoutMap)
+	for _, tc := range []struct {
+		title string
+		opt   []joiner.LoadOption
+	}{
+		{
+			title: "plain",
+		},
+		{
+			title: "dedup first",
+			opt: []joiner.LoadOption{
+				joiner.WithColumns([]int{0}),
+				joiner.WithUniqueKeys(joiner.UniqueKey{Column: 0, Policy: joiner.DuplicateFirst}),
+			},
+		},
+		{
+			title: "dedup last",
+			opt: []joiner.LoadOption{
+				joiner.WithColumns([]int{0}),
+				joiner.WithUniqueKeys(joiner.UniqueKey{Column: 0, Policy: joiner.DuplicateLast}),
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			want := loadLayout(t, content, joiner.LayoutMap, tc.opt...)
+			got := loadLayout(t, content, joiner.LayoutCompact, tc.opt...)
+			assert.Equal(t, want.Stats(), got.Stats())
+			for _, k := range []string{"k1", "k2", "k3", "k4"} {
+				assert.Equal(t, items(want, k), items(got, k), "key %s", k)
+			}
+			assert.Equal(t, all(want), all(got))
+		})
+	}
+
+	assert.Equal(t, []string{"k1 0 5", "k1 10 5", "k1 21 5"}, items(want, "k1"))
+
+	t.Run("parse", func(t *testing.T) {
+		for _, l := range []joiner.IndexLayout{joiner.LayoutCompact, joiner.LayoutMap} {
+			got, err := joiner.ParseIndexLayout(l.String())
+			assert.Nil(t, err)
+			assert.Equal(t, l, got)
+		}
+		_, err := joiner.ParseIndexLayout("tree")
+		assert.ErrorIs(t, err, joiner.ErrUnknownIndexLayout)
+	})
+}
+
+func BenchmarkIndexLayout(b *testing.B) {
+	const (
+		rows = 200000
+		keys = 50000
+	)
+	var sb strings.Builder
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&sb, "key%08d,value%d,%s\n", i%keys, i, strings.Repeat("x", 20))
+	}
+	content := sb.String()
+
+	for _, layout := range []joiner.IndexLayout{joiner.LayoutMap, joiner.LayoutCompact} {
+		b.Run(layout.String(), func(b *testing.B) {
+			var (
+				before, after runtime.MemStats
+				heap          uint64
+			)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				idx := loadLayout(b, content, layout)
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				heap += after.HeapAlloc - before.HeapAlloc
+				runtime.KeepAlive(idx)
+			}
+			b.ReportMetric(float64(heap)/float64(b.N)/rows, "heap-B/row")
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package joiner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/berquerant/joiny/async"
+)
+
+// DiffKind is the classification of the rows of Diff.
+type DiffKind int
+
+const (
+	// DiffOnlyLeft means that the row exists only in the left source.
+	DiffOnlyLeft DiffKind = iota
+	// DiffOnlyRight means that the row exists only in the right source.
+	DiffOnlyRight
+	// DiffChanged means that the rows of the same key have the different columns.
+	DiffChanged
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffOnlyLeft:
+		return "only-left"
+	case DiffOnlyRight:
+		return "only-right"
+	case DiffChanged:
+		return "changed"
+	default:
+		return fmt.Sprintf("DiffKind(%d)", int(k))
+	}
+}
+
+//go:generate go run github.com/berquerant/dataclass@v0.3.1 -type DiffResult -field "Kind DiffKind|Key string|Left []string|Right []string|Columns []int|Err error" -output diff_dataclass_diffresult_generated.go
+
+type Differ interface {
+	// Diff compares the rows of the left and the right by the keys.
+	// The rows of the same key are paired in the order of appearance,
+	// unpaired rows are only-left or only-right.
+	// The results are ordered by the appearance in the left, then in the right.
+	// Left of the result is nil if only-right, Right is nil if only-left.
+	// Columns of the result are the one-based columns that differ if changed.
+	// The diff stops at the first error.
+	Diff(ctx context.Context) <-chan DiffResult
+}
+
+// NewDiffer returns a new Differ, the rows are split into fields by the sources of the indexes.
+func NewDiffer(left, right Index) Differ {
+	return &differ{
+		left:  left,
+		right: right,
+	}
+}
+
+type differ struct {
+	left  Index
+	right Index
+}
+
+// keysInOrder returns the distinct keys of the index in the order of appearance.
+func keysInOrder(idx Index) []string {
+	var items []Item
+	for item := range idx.Items() {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Offset() < items[j].Offset() })
+	var (
+		keys = []string{}
+		seen = make(map[string]bool)
+	)
+	for _, item := range items {
+		if seen[item.Key()] {
+			continue
+		}
+		seen[item.Key()] = true
+		keys = append(keys, item.Key())
+	}
+	return keys
+}
+
+func (d *differ) read(idx Index, items []Item) ([][]string, error) {
+	r := make([][]string, len(items))
+	for i, item := range items {
+		scanned, err := idx.Read(item)
+		if err != nil {
+			return nil, err
+		}
+		if r[i], err = idx.Split(scanned.Line()); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// DiffColumns returns the one-based columns that differ between left and right.
+func DiffColumns(left, right []string) []int {
+	var r []int
+	for i := 0; i < max(len(left), len(right)); i++ {
+		if i >= len(left) || i >= len(right) || left[i] != right[i] {
+			r = append(r, i+1)
+		}
+	}
+	return r
+}
+
+// diffKey compares the rows of the key.
+func (d *differ) diffKey(key string) ([]DiffResult, error) {
+	lItems, _ := d.left.Get(key)
+	rItems, _ := d.right.Get(key)
+	lRows, err := d.read(d.left, lItems)
+	if err != nil {
+		return nil, err
+	}
+	rRows, err := d.read(d.right, rItems)
+	if err != nil {
+		return nil, err
+	}
+
+	var r []DiffResult
+	for i := 0; i < max(len(lRows), len(rRows)); i++ {
+		switch {
+		case i >= len(rRows):
+			r = append(r, NewDiffResult(DiffOnlyLeft, key, lRows[i], nil, nil, nil))
+		case i >= len(lRows):
+			r = append(r, NewDiffResult(DiffOnlyRight, key, nil, rRows[i], nil, nil))
+		default:
+			if cols := DiffColumns(lRows[i], rRows[i]); len(cols) > 0 {
+				r = append(r, NewDiffResult(DiffChanged, key, lRows[i], rRows[i], cols, nil))
+			}
+		}
+	}
+	return r, nil
+}
+
+func (d *differ) Diff(ctx context.Context) <-chan DiffResult {
+	resultC := make(chan DiffResult, 100)
+	go func() {
+		defer close(resultC)
+		fail := func(err error) {
+			async.Send(ctx, resultC, NewDiffResult(0, "", nil, nil, nil, fmt.Errorf("Diff: %w", err)))
+		}
+
+		lKeys := keysInOrder(d.left)
+		rKeys := keysInOrder(d.right)
+
+		var keys []string
+		keys = append(keys, lKeys...)
+		for _, k := range rKeys {
+			if _, ok := d.left.Get(k); !ok {
+				keys = append(keys, k)
+			}
+		}
+
+		for _, k := range keys {
+			results, err := d.diffKey(k)
+			if err != nil {
+				fail(err)
+				return
+			}
+			for _, x := range results {
+				if !async.Send(ctx, resultC, x) {
+					return
+				}
+			}
+		}
+	}()
+	return resultC
+}
@@ -0,0 +1,120 @@
+package joiner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
+	"github.com/berquerant/joiny/temporary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffColumns(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		left  []string
+		right []string
+		want  []int
+	}{
+		{
+			title: "same",
+			left:  []string{"a", "b"},
+			right: []string{"a", "b"},
+		},
+		{
+			title: "changed",
+			left:  []string{"a", "b", "c"},
+			right: []string{"a", "x", "y"},
+			want:  []int{2, 3},
+		},
+		{
+			title: "longer right",
+			left:  []string{"a"},
+			right: []string{"a", "b"},
+			want:  []int{2},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.want, joiner.DiffColumns(tc.left, tc.right))
+		})
+	}
+}
+
+func TestDiffer(t *testing.T) {
+	newIndex := func(t *testing.T, content string) joiner.Index {
+		f, err := temporary.NewFile()
+		if err != nil {
+			t.Fatalf("create tmp file %v", err)
+		}
+		t.Cleanup(func() { f.Close() })
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("write to tmp file %v", err)
+		}
+		indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, ","), 10).Load(context.TODO(), func(val string) (string, error) {
+			return val[:1], nil
+		})
+		if err != nil {
+			t.Fatalf("new index %v", err)
+		}
+		return indexes[0]
+	}
+
+	type diff struct {
+		kind    joiner.DiffKind
+		key     string
+		left    []string
+		right   []string
+		columns []int
+	}
+
+	for _, tc := range []struct {
+		title string
+		left  string
+		right string
+		want  []diff
+	}{
+		{
+			title: "no diff",
+			left:  "1,a\n2,b\n",
+			right: "2,b\n1,a\n",
+		},
+		{
+			title: "classify",
+			left:  "1,a\n2,b\n3,c\n",
+			right: "4,d\n2,x\n1,a\n",
+			want: []diff{
+				{kind: joiner.DiffChanged, key: "2", left: []string{"2", "b"}, right: []string{"2", "x"}, columns: []int{2}},
+				{kind: joiner.DiffOnlyLeft, key: "3", left: []string{"3", "c"}},
+				{kind: joiner.DiffOnlyRight, key: "4", right: []string{"4", "d"}},
+			},
+		},
+		{
+			title: "duplicated keys",
+			left:  "1,a\n1,b\n",
+			right: "1,a\n1,c\n1,d\n",
+			want: []diff{
+				{kind: joiner.DiffChanged, key: "1", left: []string{"1", "b"}, right: []string{"1", "c"}, columns: []int{2}},
+				{kind: joiner.DiffOnlyRight, key: "1", right: []string{"1", "d"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			d := joiner.NewDiffer(newIndex(t, tc.left), newIndex(t, tc.right))
+			var got []diff
+			for x := range d.Diff(context.TODO()) {
+				if err := x.Err(); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, diff{
+					kind:    x.Kind(),
+					key:     x.Key(),
+					left:    x.Left(),
+					right:   x.Right(),
+					columns: x.Columns(),
+				})
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -1,17 +1,19 @@
 package joiner
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"iter"
+	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/berquerant/joiny/async"
 	"github.com/berquerant/joiny/logx"
+	"github.com/berquerant/joiny/source"
 )
 
 // KeyFunc extracts a key from a line.
@@ -21,6 +23,8 @@ type KeyFunc func(string) (string, error)
 
 //go:generate go run github.com/berquerant/dataclass@v0.3.1 -type ScannedItem -field "Line string|Item Item" -output index_dataclass_scanneditem_generated.go
 
+//go:generate go run github.com/berquerant/dataclass@v0.3.1 -type ScanResult -field "Item ScannedItem|Err error" -output index_dataclass_scanresult_generated.go
+
 type itemListMap map[string][]Item
 
 func (m itemListMap) get(key string) ([]Item, bool) {
@@ -32,27 +36,91 @@ func (m itemListMap) add(key string, item Item) {
 	m[key] = append(m[key], item)
 }
 
+func (m itemListMap) has(key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func (m itemListMap) allKeys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for k := range m {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func (m itemListMap) all() iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		for _, itemList := range m {
+			for _, item := range itemList {
+				if !yield(item) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// IndexStats is the statistics of the index.
+type IndexStats struct {
+	// Rows is the number of the indexed lines.
+	Rows int
+	// Distinct is the number of the distinct keys.
+	Distinct int
+	// MaxFanOut is the max number of the lines of a key.
+	MaxFanOut int
+}
+
+func (m itemListMap) stats() IndexStats {
+	s := IndexStats{
+		Distinct: len(m),
+	}
+	for _, items := range m {
+		s.Rows += len(items)
+		s.MaxFanOut = max(s.MaxFanOut, len(items))
+	}
+	return s
+}
+
 // Index is an in-memory word-to-lines index.
 // This is read-only, underlying data source (file) must be also read-only.
 type Index interface {
 	KeyFunc() KeyFunc
+	Stats() IndexStats
 	Get(key string) ([]Item, bool)
 	Read(item Item) (ScannedItem, error)
-	Scan(ctx context.Context) <-chan ScannedItem
+	// Split splits a line into fields by the rule of the source.
+	// The missing key columns are empty if the line is indexed by MalformedEmpty.
+	Split(line string) ([]string, error)
+	// Scan reads all items.
+	// The scan stops at the first error.
+	Scan(ctx context.Context) <-chan ScanResult
 	AllItems(ctx context.Context) <-chan Item
+	// Lines is the iterator version of Scan.
+	Lines() iter.Seq2[ScannedItem, error]
+	// Items is the iterator version of AllItems.
+	Items() iter.Seq[Item]
 }
 
 type index struct {
-	data async.CachedReader
-	key  KeyFunc
-	val  itemListMap
+	src   source.Source
+	data  async.CachedReader
+	key   KeyFunc
+	val   itemStore
+	stats IndexStats
+	width int // the min number of the fields of a line, the missing fields are empty, see MalformedEmpty
 }
 
-func newIndex(data async.CachedReader, key KeyFunc, val itemListMap) Index {
+func newIndex(src source.Source, data async.CachedReader, key KeyFunc, val itemStore, width int) Index {
 	return &index{
-		data: data,
-		key:  key,
-		val:  val,
+		src:   src,
+		data:  data,
+		key:   key,
+		val:   val,
+		stats: val.stats(),
+		width: width,
 	}
 }
 
@@ -60,109 +128,491 @@ type IndexLoader interface {
 	Load(ctx context.Context, key ...KeyFunc) ([]Index, error)
 }
 
-func NewIndexLoader(data async.ReadSeeker, indexCacheSize int) IndexLoader {
+type loadConfig struct {
+	source       int
+	columns      []int
+	malformed    MalformedPolicy
+	rejecter     Rejecter
+	unique       map[cacheKey]DuplicatePolicy
+	chunkThreads int
+	minChunkSize int64
+	layout       IndexLayout
+	prefilter    bool
+	progress     *Progress
+	timings      *Timings
+}
+
+// LoadOption configures IndexLoader.
+type LoadOption func(*loadConfig)
+
+// WithSource sets the zero-based source number of the data, used to report the rejections.
+func WithSource(src int) LoadOption {
+	return func(c *loadConfig) {
+		c.source = src
+	}
+}
+
+// WithColumns sets the zero-based columns of the keys of Load, used to find the unique columns.
+func WithColumns(cols []int) LoadOption {
+	return func(c *loadConfig) {
+		c.columns = cols
+	}
+}
+
+// WithUniqueKeys requires the keys of the columns to be unique.
+// Requires WithSource and WithColumns to find the columns.
+// Each column chooses the lines to drop from all the lines by its policy, the lines are dropped from all the columns,
+// Load fails with ErrConflictingDuplicates if a key of a unique column loses all its lines.
+func WithUniqueKeys(keys ...UniqueKey) LoadOption {
+	return func(c *loadConfig) {
+		for _, k := range keys {
+			c.unique[cacheKey{
+				src: k.Source,
+				col: k.Column,
+			}] = k.Policy
+		}
+	}
+}
+
+// duplicatePolicy returns the policy of the i-th key of Load if the key should be unique.
+func (c *loadConfig) duplicatePolicy(i int) (DuplicatePolicy, bool) {
+	if i >= len(c.columns) {
+		return 0, false
+	}
+	p, ok := c.unique[cacheKey{
+		src: c.source,
+		col: c.columns[i],
+	}]
+	return p, ok
+}
+
+// WithMalformedPolicy sets how to handle the lines whose keys cannot be extracted.
+// Default is MalformedFail.
+func WithMalformedPolicy(p MalformedPolicy) LoadOption {
+	return func(c *loadConfig) {
+		c.malformed = p
+	}
+}
+
+// WithRejecter sets the destination of the malformed lines.
+func WithRejecter(r Rejecter) LoadOption {
+	return func(c *loadConfig) {
+		c.rejecter = r
+	}
+}
+
+// WithChunkThreads indexes a source by n goroutines, splitting it into byte ranges aligned on lines.
+// Only the sources that implement source.Ranged are split.
+// Default is 1.
+func WithChunkThreads(n int) LoadOption {
+	return func(c *loadConfig) {
+		c.chunkThreads = n
+	}
+}
+
+// WithMinChunkSize sets the min bytes of a byte range of WithChunkThreads.
+// Default is 64 MiB.
+func WithMinChunkSize(n int64) LoadOption {
+	return func(c *loadConfig) {
+		c.minChunkSize = n
+	}
+}
+
+// WithIndexLayout sets the in-memory representation of the indexes.
+// Default is LayoutCompact.
+func WithIndexLayout(layout IndexLayout) LoadOption {
+	return func(c *loadConfig) {
+		c.layout = layout
+	}
+}
+
+// WithPrefilter makes CacheBuilder find the rows whose keys are not in the other sides of the relations,
+// so that the joins drop them before reading them, see Cache.Prefilter.
+// The location list of CacheBuilder should be the pairs of the relations.
+// Default is false.
+func WithPrefilter(v bool) LoadOption {
+	return func(c *loadConfig) {
+		c.prefilter = v
+	}
+}
+
+// WithProgress counts the records loaded from the source of WithSource.
+func WithProgress(p *Progress) LoadOption {
+	return func(c *loadConfig) {
+		c.progress = p
+	}
+}
+
+// WithTimings records the elapsed times of the phases of IndexLoader and CacheBuilder.
+func WithTimings(t *Timings) LoadOption {
+	return func(c *loadConfig) {
+		c.timings = t
+	}
+}
+
+// loadProgress returns the progress of the source, nil if not counted.
+func (c *loadConfig) loadProgress() *LoadProgress {
+	if c.progress == nil {
+		return nil
+	}
+	return c.progress.Source(c.source)
+}
+
+func newLoadConfig(opt ...LoadOption) *loadConfig {
+	c := &loadConfig{
+		malformed:    MalformedFail,
+		rejecter:     NewRejecter(io.Discard),
+		unique:       make(map[cacheKey]DuplicatePolicy),
+		chunkThreads: 1,
+		minChunkSize: 64 << 20,
+		layout:       LayoutCompact,
+	}
+	for _, f := range opt {
+		f(c)
+	}
+	return c
+}
+
+// NewIndexLoader returns a new IndexLoader that indexes the records of src.
+// The indexes of a Load share a read cache of src up to cacheBytes.
+func NewIndexLoader(src source.Source, cacheBytes int, opt ...LoadOption) IndexLoader {
 	return &indexLoader{
-		data:           data,
-		indexCacheSize: indexCacheSize,
+		src:        src,
+		cacheBytes: cacheBytes,
+		config:     newLoadConfig(opt...),
 	}
 }
 
 type indexLoader struct {
-	data           async.ReadSeeker
-	indexCacheSize int
+	src        source.Source
+	cacheBytes int
+	config     *loadConfig
 }
 
-func (ldr *indexLoader) Load(ctx context.Context, key ...KeyFunc) ([]Index, error) {
-	logx.G().Debug("IndexLoader: begin", logx.I("index", len(key)))
+// keys extracts the keys of the line.
+// Returns false if the line should be skipped.
+func (ldr *indexLoader) keys(key []KeyFunc, line string, lineCount int, reject func(Rejection) error) ([]string, bool, error) {
+	var (
+		keys = make([]string, len(key))
+		errs []error
+	)
+	for i, kf := range key {
+		k, err := kf(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key[%d]: %w", i, err))
+			continue
+		}
+		keys[i] = k
+	}
+	if len(errs) == 0 {
+		return keys, true, nil
+	}
 
-	vals := make([]itemListMap, len(key))
-	for i := range vals {
-		vals[i] = make(map[string][]Item)
+	reason := errors.Join(errs...)
+	if err := reject(NewRejection(ldr.config.source, lineCount, line, reason)); err != nil {
+		return nil, false, err
 	}
+	switch ldr.config.malformed {
+	case MalformedSkip:
+		return nil, false, nil
+	case MalformedEmpty:
+		return keys, true, nil
+	default:
+		return nil, false, reason
+	}
+}
 
-	if err := ldr.data.Do(func(data io.ReadSeeker) error {
-		if _, err := data.Seek(0, os.SEEK_SET); err != nil {
-			return fmt.Errorf("init: %w", err)
+// chunks splits the source into the byte ranges to be indexed in parallel.
+func (ldr *indexLoader) chunks() ([]iter.Seq2[source.Record, error], error) {
+	single := []iter.Seq2[source.Record, error]{ldr.src.Records()}
+	r, ok := ldr.src.(source.Ranged)
+	if !ok || ldr.config.chunkThreads < 2 {
+		return single, nil
+	}
+	size, err := r.Size()
+	if err != nil {
+		return nil, err
+	}
+	n := min(int64(ldr.config.chunkThreads), size/max(ldr.config.minChunkSize, 1))
+	if n < 2 {
+		return single, nil
+	}
+
+	var (
+		step   = size / n
+		chunks = make([]iter.Seq2[source.Record, error], n)
+	)
+	for i := range chunks {
+		end := int64(i+1) * step
+		if i == len(chunks)-1 {
+			end = math.MaxInt64
 		}
+		chunks[i] = r.RecordsRange(int64(i)*step, end)
+	}
+	logx.C(logx.Index).Debug("IndexLoader: chunks",
+		logx.I("source", ldr.config.source+1),
+		logx.I("bytes", size),
+		logx.I("chunks", n),
+	)
+	return chunks, nil
+}
 
-		var (
-			offset    int64
-			isEOF     bool
-			lineCount int
-			itemCount = make([]int, len(key))
-			keySize   = make([]int, len(key))
-			startAt   = time.Now()
-			r         = bufio.NewReader(data)
-		)
-		for !isEOF {
-			if async.Done(ctx) {
-				return fmt.Errorf("load: %w", ctx.Err())
-			}
-			line, err := r.ReadBytes('\n')
-			isEOF = errors.Is(err, io.EOF)
-			if err != nil && !isEOF {
-				return fmt.Errorf("read: offset %d %w", offset, err)
+// chunkLoad is the result of indexing a chunk.
+// Line numbers are relative to the head of the chunk.
+type chunkLoad struct {
+	vals       []*rowTable
+	lines      int
+	skipped    int
+	rejections []Rejection // rejections of the chunks other than the first
+	err        error
+	errLine    int // 0 if err is not of a line
+	errRecord  source.Record
+}
+
+func (ldr *indexLoader) loadChunk(ctx context.Context, records iter.Seq2[source.Record, error], key []KeyFunc, reject func(Rejection) error) *chunkLoad {
+	c := &chunkLoad{
+		vals: make([]*rowTable, len(key)),
+	}
+	for i := range c.vals {
+		c.vals[i] = newRowTable()
+	}
+	progress := ldr.config.loadProgress()
+
+	for rec, err := range records {
+		if err != nil {
+			c.err = err
+			return c
+		}
+		if async.Done(ctx) {
+			c.err = fmt.Errorf("load: %w", ctx.Err())
+			return c
+		}
+
+		c.lines++
+		offset, size := rec.Offset(), rec.Size()
+		if progress != nil {
+			progress.add(size)
+		}
+		lineStr := rec.Text()
+		if lineStr == "" {
+			continue
+		}
+
+		keys, ok, err := ldr.keys(key, lineStr, c.lines, reject)
+		if err != nil {
+			c.err = err
+			c.errLine = c.lines
+			c.errRecord = rec
+			return c
+		}
+		if !ok {
+			c.skipped++
+			continue
+		}
+		for i, k := range keys {
+			logx.C(logx.Index).Debug("IndexLoader: new item",
+				logx.I("item", i),
+				logx.S("line", lineStr),
+				logx.I("size", size),
+				logx.I("offset", offset),
+				logx.I("keysize", len(k)),
+				logx.S("key", k),
+			)
+			if err := c.vals[i].add(k, offset, size); err != nil {
+				c.err = err
+				c.errLine = c.lines
+				c.errRecord = rec
+				return c
 			}
+		}
+	}
+	return c
+}
 
-			lineCount++
-			size := len(line)
-			lineStr := strings.TrimRight(string(line), "\n")
-			if lineStr == "" {
-				offset += int64(size)
-				continue
+// loadChunks indexes the chunks concurrently.
+// The rejections are reported in the order of the lines.
+func (ldr *indexLoader) loadChunks(ctx context.Context, chunks []iter.Seq2[source.Record, error], key []KeyFunc) ([]*rowTable, int, int, error) {
+	var (
+		results = make([]*chunkLoad, len(chunks))
+		cancels = make([]context.CancelFunc, len(chunks))
+		ctxs    = make([]context.Context, len(chunks))
+		wg      sync.WaitGroup
+	)
+	for i := range chunks {
+		ctxs[i], cancels[i] = context.WithCancel(ctx)
+		defer cancels[i]()
+	}
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var (
+				buffered []Rejection
+				reject   = ldr.config.rejecter.Reject
+			)
+			if i > 0 {
+				// line numbers are unknown until the preceding chunks are done
+				reject = func(r Rejection) error {
+					buffered = append(buffered, r)
+					return nil
+				}
+			}
+			r := ldr.loadChunk(ctxs[i], chunk, key, reject)
+			r.rejections = buffered
+			results[i] = r
+			if r.err != nil {
+				// the preceding chunks are required to count the lines
+				for _, cancel := range cancels[i+1:] {
+					cancel()
+				}
 			}
+		}()
+	}
+	wg.Wait()
 
-			for i, kf := range key {
-				k, err := kf(lineStr)
-				if err != nil {
-					return fmt.Errorf("key[%d]: %s offset %d %w", i, lineStr, offset, err)
+	var lines, skipped int
+	for i, r := range results {
+		if i > 0 {
+			for _, x := range r.rejections {
+				if err := ldr.config.rejecter.Reject(NewRejection(x.Source(), x.Line()+lines, x.Text(), x.Reason())); err != nil {
+					return nil, 0, 0, err
 				}
-				kSize := len(k)
-				logx.G().Debug("IndexLoader: new item",
-					logx.I("item", i),
-					logx.S("line", lineStr),
-					logx.I("size", size),
-					logx.I("offset", offset),
-					logx.I("keysize", kSize),
-					logx.S("key", k),
-				)
-				vals[i].add(k, NewItem(k, offset, size))
-				itemCount[i]++
-				keySize[i] += kSize
 			}
-			offset += int64(size)
 		}
+		if r.err != nil {
+			if r.errLine == 0 {
+				return nil, 0, 0, r.err
+			}
+			return nil, 0, 0, fmt.Errorf("line %d offset %d %s: %w", r.errLine+lines, r.errRecord.Offset(), r.errRecord.Text(), r.err)
+		}
+		lines += r.lines
+		skipped += r.skipped
+	}
 
-		for i, ic := range itemCount {
-			logx.G().Debug("IndexLoader: done", logx.I("key_index", i), logx.I("size", len(key)), logx.I("item", ic))
+	vals := results[0].vals
+	for _, r := range results[1:] {
+		for i, val := range r.vals {
+			vals[i].merge(val)
 		}
-		logx.G().Debug("IndexLoader: done",
-			logx.I("bytes", offset),
-			logx.I("key", len(vals[0])),
-			logx.I("line", lineCount),
-			logx.D("elapsed", time.Since(startAt)),
-		)
-		return nil
-	}); err != nil {
+	}
+	return vals, lines, skipped, nil
+}
+
+func (ldr *indexLoader) Load(ctx context.Context, key ...KeyFunc) ([]Index, error) {
+	logx.C(logx.Index).Debug("IndexLoader: begin", logx.I("index", len(key)))
+	startAt := time.Now()
+	if progress := ldr.config.loadProgress(); progress != nil {
+		if r, ok := ldr.src.(source.Ranged); ok {
+			if size, err := r.Size(); err == nil {
+				progress.total.Store(size)
+			}
+		}
+		defer progress.done.Store(true)
+	}
+	defer ldr.config.timings.since("load", ldr.config.source, startAt)
+
+	chunks, err := ldr.chunks()
+	if err != nil {
+		return nil, fmt.Errorf("IndexLoader: %w", err)
+	}
+	vals, lineCount, rejectCount, err := ldr.loadChunks(ctx, chunks, key)
+	if err != nil {
 		return nil, fmt.Errorf("IndexLoader: %w", err)
 	}
+	ldr.config.timings.since("load.read", ldr.config.source, startAt)
+	dedupAt := time.Now()
 
-	indexList := make([]Index, len(vals))
-	for i, val := range vals {
-		c, err := async.NewCachedReader(ldr.indexCacheSize, ldr.data)
+	// a line dropped by a unique column is dropped from all the indexes of the source,
+	// the unique columns choose the lines from all the lines independently
+	var (
+		dead   = make(map[int64]bool)
+		unique []int
+	)
+	for i := range vals {
+		policy, ok := ldr.config.duplicatePolicy(i)
+		if !ok {
+			continue
+		}
+		unique = append(unique, i)
+		report, err := vals[i].dedup(policy, dead)
 		if err != nil {
-			return nil, fmt.Errorf("IndexLoader: %w", err)
+			return nil, fmt.Errorf("IndexLoader: source %d column %d: %w", ldr.config.source+1, ldr.config.columns[i]+1, err)
+		}
+		if report.Keys > 0 {
+			logx.C(logx.Index).Warn("IndexLoader: duplicated keys",
+				logx.I("source", ldr.config.source+1),
+				logx.I("column", ldr.config.columns[i]+1),
+				logx.S("policy", policy.String()),
+				logx.S("keys", report.String()),
+			)
 		}
-		indexList[i] = &index{
-			data: c,
-			key:  key[i],
-			val:  val,
+	}
+	for _, i := range unique {
+		// the kept line of the key is dropped by another unique column
+		if k, ok := vals[i].lostKey(dead); ok {
+			return nil, fmt.Errorf("IndexLoader: %w: source %d column %d loses key %q by the duplicated keys of the other unique columns",
+				ErrConflictingDuplicates, ldr.config.source+1, ldr.config.columns[i]+1, k)
+		}
+	}
+	for _, val := range vals {
+		val.drop(dead)
+	}
+	ldr.config.timings.since("load.dedup", ldr.config.source, dedupAt)
+
+	storeAt := time.Now()
+	stores := make([]itemStore, len(vals))
+	for i, val := range vals {
+		stores[i] = val.store(ldr.config.layout)
+		vals[i] = nil // release the rows as soon as possible
+		stats := stores[i].stats()
+		logx.C(logx.Index).Debug("IndexLoader: done",
+			logx.I("key_index", i),
+			logx.I("size", len(key)),
+			logx.I("item", stats.Rows),
+			logx.I("distinct", stats.Distinct),
+			logx.I("max_fanout", stats.MaxFanOut),
+		)
+	}
+	ldr.config.timings.since("load.store", ldr.config.source, storeAt)
+	logx.C(logx.Index).Debug("IndexLoader: done",
+		logx.I("key", stores[0].stats().Distinct),
+		logx.S("layout", ldr.config.layout.String()),
+		logx.I("line", lineCount),
+		logx.I("skipped", rejectCount),
+		logx.I("chunks", len(chunks)),
+		logx.D("elapsed", time.Since(startAt)),
+	)
+
+	var c async.CachedReader = ldr.src
+	if !source.InMemory(ldr.src) {
+		c = async.NewSizedLRUReader(ldr.cacheBytes, ldr.src)
+	}
+	var width int
+	if ldr.config.malformed == MalformedEmpty {
+		// the lines are indexed with the empty keys in place of the missing columns
+		for _, col := range ldr.config.columns {
+			width = max(width, col+1)
 		}
 	}
+	indexList := make([]Index, len(stores))
+	for i, store := range stores {
+		indexList[i] = newIndex(ldr.src, c, key[i], store, width)
+	}
 	return indexList, nil
 }
 
-func (idx *index) KeyFunc() KeyFunc { return idx.key }
+func (idx *index) KeyFunc() KeyFunc  { return idx.key }
+func (idx *index) Stats() IndexStats { return idx.stats }
+func (idx *index) Split(line string) ([]string, error) {
+	fields, err := idx.src.Split(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < idx.width {
+		fields = append(fields, make([]string, idx.width-len(fields))...)
+	}
+	return fields, nil
+}
 
 func (idx *index) Get(key string) ([]Item, bool) {
 	// no lock because index is readonly
@@ -176,25 +626,34 @@ func (idx *index) Read(item Item) (ScannedItem, error) {
 	}
 
 	r := strings.TrimRight(string(b), "\n")
-	logx.G().Trace("Read Index", logx.Any("item", item), logx.Any("return", r))
+	logx.C(logx.Index).Trace("Read Index", logx.Any("item", item), logx.Any("return", r))
 	return NewScannedItem(r, item), nil
 }
 
-func (idx *index) Scan(ctx context.Context) <-chan ScannedItem {
-	resultC := make(chan ScannedItem, 100)
+func (idx *index) Lines() iter.Seq2[ScannedItem, error] {
+	return func(yield func(ScannedItem, error) bool) {
+		for item := range idx.Items() {
+			r, err := idx.Read(item)
+			if err != nil {
+				yield(nil, fmt.Errorf("Scan: %w", err))
+				return
+			}
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (idx *index) Items() iter.Seq[Item] { return idx.val.all() }
+
+func (idx *index) Scan(ctx context.Context) <-chan ScanResult {
+	resultC := make(chan ScanResult, 100)
 	go func() {
 		defer close(resultC)
-		for _, itemList := range idx.val {
-			for _, item := range itemList {
-				if async.Done(ctx) {
-					return
-				}
-				r, err := idx.Read(item)
-				if err != nil {
-					logx.G().Error("Scan: failed to read", logx.Any("item", item), logx.Err(err))
-					return
-				}
-				resultC <- r
+		for r, err := range idx.Lines() {
+			if !async.Send(ctx, resultC, NewScanResult(r, err)) {
+				return
 			}
 		}
 	}()
@@ -205,12 +664,9 @@ func (idx *index) AllItems(ctx context.Context) <-chan Item {
 	resultC := make(chan Item, 100)
 	go func() {
 		defer close(resultC)
-		for _, itemList := range idx.val {
-			for _, item := range itemList {
-				if async.Done(ctx) {
-					return
-				}
-				resultC <- item
+		for item := range idx.Items() {
+			if !async.Send(ctx, resultC, item) {
+				return
 			}
 		}
 	}()
@@ -2,12 +2,13 @@ package joiner_test
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
 
-	"github.com/berquerant/joiny/async"
 	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
 	"github.com/berquerant/joiny/temporary"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,7 +27,7 @@ k2 v4
 	if _, err := f.Write([]byte(content)); err != nil {
 		t.Fatalf("write to tmp file %v", err)
 	}
-	indexes, err := joiner.NewIndexLoader(async.NewReadSeeker(f), 10).Load(context.TODO(), func(val string) (string, error) {
+	indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, " "), 10).Load(context.TODO(), func(val string) (string, error) {
 		return strings.Split(val, " ")[0], nil
 	})
 	if err != nil {
@@ -78,6 +79,14 @@ k2 v4
 		})
 	}
 
+	t.Run("stats", func(t *testing.T) {
+		assert.Equal(t, joiner.IndexStats{
+			Rows:      4,
+			Distinct:  3,
+			MaxFanOut: 2,
+		}, index.Stats())
+	})
+
 	t.Run("scan", func(t *testing.T) {
 		want := []string{
 			"k1 v1",
@@ -87,10 +96,258 @@ k2 v4
 		}
 		got := []string{}
 		for item := range index.Scan(context.TODO()) {
-			got = append(got, item.Line())
+			if err := item.Err(); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, item.Item().Line())
 		}
 		sort.Strings(got)
 		sort.Strings(want)
 		assert.Equal(t, want, got)
 	})
 }
+
+func TestIndexSeq(t *testing.T) {
+	const content = `k1 v1
+k2 v2
+k1 v3
+`
+	f, err := temporary.NewFile()
+	if err != nil {
+		t.Fatalf("create tmp file %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatalf("write to tmp file %v", err)
+	}
+	indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, " "), 10).Load(context.TODO(), func(val string) (string, error) {
+		return strings.Split(val, " ")[0], nil
+	})
+	if err != nil {
+		t.Fatalf("new index %v", err)
+	}
+	index := indexes[0]
+
+	t.Run("lines", func(t *testing.T) {
+		got := []string{}
+		for item, err := range index.Lines() {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, item.Line())
+		}
+		sort.Strings(got)
+		assert.Equal(t, []string{"k1 v1", "k1 v3", "k2 v2"}, got)
+	})
+
+	t.Run("items", func(t *testing.T) {
+		var count int
+		for range index.Items() {
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		assert.Equal(t, 2, count)
+	})
+}
+
+func TestIndexLoaderMalformed(t *testing.T) {
+	const content = `k1 v1
+k2
+k3 v3
+`
+	keyFunc := func(val string) (string, error) {
+		ss := strings.Split(val, " ")
+		if len(ss) < 2 {
+			return "", joiner.ErrNewKeyFailure
+		}
+		return ss[1], nil
+	}
+
+	for _, tc := range []struct {
+		title  string
+		policy joiner.MalformedPolicy
+		err    error
+		want   map[string][]string
+	}{
+		{
+			title:  "fail",
+			policy: joiner.MalformedFail,
+			err:    joiner.ErrNewKeyFailure,
+		},
+		{
+			title:  "skip",
+			policy: joiner.MalformedSkip,
+			want: map[string][]string{
+				"v1": {"k1 v1"},
+				"v3": {"k3 v3"},
+				"":   nil,
+			},
+		},
+		{
+			title:  "empty",
+			policy: joiner.MalformedEmpty,
+			want: map[string][]string{
+				"v1": {"k1 v1"},
+				"v3": {"k3 v3"},
+				"":   {"k2"},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			f, err := temporary.NewFile()
+			if err != nil {
+				t.Fatalf("create tmp file %v", err)
+			}
+			defer f.Close()
+			if _, err := f.Write([]byte(content)); err != nil {
+				t.Fatalf("write to tmp file %v", err)
+			}
+
+			var rejected strings.Builder
+			rejecter := joiner.NewRejecter(&rejected)
+			indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, " "), 10,
+				joiner.WithSource(1),
+				joiner.WithMalformedPolicy(tc.policy),
+				joiner.WithRejecter(rejecter),
+			).Load(context.TODO(), keyFunc)
+			assert.Equal(t, 1, rejecter.Count())
+			assert.True(t, strings.HasPrefix(rejected.String(), "2\t2\t"), "rejected %q", rejected.String())
+			assert.True(t, strings.HasSuffix(rejected.String(), "\tk2\n"), "rejected %q", rejected.String())
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			index := indexes[0]
+			for key, want := range tc.want {
+				items, _ := index.Get(key)
+				var got []string
+				for _, item := range items {
+					scanned, err := index.Read(item)
+					if err != nil {
+						t.Fatalf("scan %v %v", item, err)
+					}
+					got = append(got, scanned.Line())
+				}
+				assert.Equal(t, want, got, "key %q", key)
+			}
+		})
+	}
+}
+
+func TestParseMalformedPolicy(t *testing.T) {
+	for _, p := range []joiner.MalformedPolicy{joiner.MalformedFail, joiner.MalformedSkip, joiner.MalformedEmpty} {
+		got, err := joiner.ParseMalformedPolicy(p.String())
+		assert.Nil(t, err)
+		assert.Equal(t, p, got)
+	}
+	_, err := joiner.ParseMalformedPolicy("ignore")
+	assert.ErrorIs(t, err, joiner.ErrUnknownMalformedPolicy)
+}
+
+func TestIndexLoaderChunks(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		if i%30 == 29 {
+			sb.WriteString(fmt.Sprintf("malformed%d\n", i))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("k%d v%d\n", i%7, i))
+	}
+	content := sb.String()
+	keyFunc := func(val string) (string, error) {
+		ss := strings.Split(val, " ")
+		if len(ss) < 2 {
+			return "", joiner.ErrNewKeyFailure
+		}
+		return ss[0], nil
+	}
+	load := func(t *testing.T, opt ...joiner.LoadOption) (joiner.Index, string, error) {
+		t.Helper()
+		f, err := temporary.NewFile()
+		if err != nil {
+			t.Fatalf("create tmp file %v", err)
+		}
+		t.Cleanup(func() { f.Close() })
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("write to tmp file %v", err)
+		}
+		var rejected strings.Builder
+		opt = append([]joiner.LoadOption{joiner.WithRejecter(joiner.NewRejecter(&rejected))}, opt...)
+		indexes, err := joiner.NewIndexLoader(source.NewDelimited(f, " "), 10, opt...).Load(context.TODO(), keyFunc)
+		if err != nil {
+			return nil, rejected.String(), err
+		}
+		return indexes[0], rejected.String(), nil
+	}
+	lines := func(t *testing.T, index joiner.Index, key string) []string {
+		t.Helper()
+		items, _ := index.Get(key)
+		r := make([]string, len(items))
+		for i, item := range items {
+			scanned, err := index.Read(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r[i] = scanned.Line()
+		}
+		return r
+	}
+	chunked := []joiner.LoadOption{joiner.WithChunkThreads(4), joiner.WithMinChunkSize(1)}
+
+	t.Run("same as sequential", func(t *testing.T) {
+		skip := joiner.WithMalformedPolicy(joiner.MalformedSkip)
+		want, wantRejected, err := load(t, skip)
+		if !assert.Nil(t, err) {
+			return
+		}
+		got, gotRejected, err := load(t, append(chunked, skip)...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, want.Stats(), got.Stats())
+		for i := 0; i < 7; i++ {
+			key := fmt.Sprintf("k%d", i)
+			assert.Equal(t, lines(t, want, key), lines(t, got, key), "key %s", key)
+		}
+		assert.Equal(t, 3, strings.Count(gotRejected, "\n"))
+		assert.Equal(t, wantRejected, gotRejected)
+		assert.Contains(t, gotRejected, "\t60\t", "line number of the rejection")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		_, rejected, err := load(t, chunked...)
+		assert.ErrorIs(t, err, joiner.ErrNewKeyFailure)
+		assert.ErrorContains(t, err, "line 30 ")
+		assert.Equal(t, 1, strings.Count(rejected, "\n"))
+	})
+}
+
+func TestIndexLoaderProgress(t *testing.T) {
+	const content = "k1,a\nk2,b\n\nk3,c\n"
+	for _, threads := range []int{1, 3} {
+		t.Run(fmt.Sprintf("threads %d", threads), func(t *testing.T) {
+			progress := joiner.NewProgress()
+			_, err := joiner.NewIndexLoader(source.NewDelimited(strings.NewReader(content), ","), 1024,
+				joiner.WithSource(1),
+				joiner.WithProgress(progress),
+				joiner.WithChunkThreads(threads),
+				joiner.WithMinChunkSize(1),
+			).Load(context.TODO(), firstColumn)
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, []int{1}, progress.Sources())
+			p := progress.Source(1)
+			assert.Equal(t, int64(len(content)), p.Bytes())
+			assert.Equal(t, int64(len(content)), p.Total())
+			assert.Equal(t, int64(4), p.Lines())
+			assert.True(t, p.Done())
+		})
+	}
+}
@@ -2,8 +2,11 @@ package joiner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"sort"
+	"sync/atomic"
 
 	"github.com/berquerant/joiny/async"
 	"github.com/berquerant/joiny/cc/joinkey"
@@ -49,80 +52,231 @@ func (s SelectItemList) Sorted() []SelectItem {
 	return r
 }
 
+//go:generate go run github.com/berquerant/dataclass@v0.3.1 -type JoinResult -field "Row SelectItemList|Err error" -output joiner_dataclass_joinresult_generated.go
+
+var (
+	ErrIndexNotFound    = errors.New("IndexNotFound")
+	ErrInconsistentRows = errors.New("InconsistentRows")
+	ErrNoRowsFound      = errors.New("NoRowsFound")
+	ErrEmptyKey         = errors.New("EmptyKey")
+)
+
 type RelationJoiner interface {
 	// FullJoin links records with cross join.
-	FullJoin(ctx context.Context, rel *joinkey.Relation) <-chan SelectItemList
+	FullJoin(ctx context.Context, rel *joinkey.Relation) <-chan JoinResult
 	// Join links given rows and the other records.
 	// Fallback to FullJoin if rowC is nil.
-	Join(ctx context.Context, rel *joinkey.Relation, rowC <-chan SelectItemList) <-chan SelectItemList
+	// The errors of the given rows are passed through.
+	// A result with an error means that the row cannot be joined, the rest of the results continue unless the error is fatal.
+	Join(ctx context.Context, rel *joinkey.Relation, rowC <-chan JoinResult) <-chan JoinResult
+	// FullJoinSeq is the iterator version of FullJoin.
+	FullJoinSeq(rel *joinkey.Relation) iter.Seq2[SelectItemList, error]
+	// JoinSeq is the iterator version of Join.
+	// Fallback to FullJoinSeq if rows is nil.
+	JoinSeq(rel *joinkey.Relation, rows iter.Seq2[SelectItemList, error]) iter.Seq2[SelectItemList, error]
 }
 
-func NewRelationJoiner(cache Cache) RelationJoiner {
+func NewRelationJoiner(cache Cache, opt ...JoinOption) RelationJoiner {
+	config := newJoinConfig(opt...)
+	var filter Prefilter = passAll{}
+	if p := cache.Prefilter(); p != nil && len(config.optional) == 0 {
+		filter = p
+	}
 	return &relationJoiner{
-		cache: cache,
+		cache:    cache,
+		filter:   filter,
+		optional: config.optional,
+		nulls:    newNullRows(),
 	}
 }
 
 type relationJoiner struct {
-	cache Cache
+	cache    Cache
+	filter   Prefilter
+	optional map[int]bool // zero-based sources
+	nulls    *nullRows
+}
+
+// withNull returns the row with the null row of the source of idx.
+func (r *relationJoiner) withNull(row SelectItemList, src int, idx Index) (SelectItemList, error) {
+	null, err := r.nulls.get(src, idx)
+	if err != nil {
+		return nil, err
+	}
+	l := row.Clone()
+	l.Set(null)
+	return l, nil
 }
 
-func (r *relationJoiner) FullJoin(ctx context.Context, rel *joinkey.Relation) <-chan SelectItemList {
-	resultC := make(chan SelectItemList, 100)
+func (r *relationJoiner) indexes(rel *joinkey.Relation) (*joinkey.Location, Index, *joinkey.Location, Index, error) {
+	lKey, rKey := rel.Left.Add(-1, -1), rel.Right.Add(-1, -1) // into zero-based
+	lIndex, ok := r.cache.Get(lKey.Src, lKey.Col)
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("%w: left %v", ErrIndexNotFound, rel.Left)
+	}
+	rIndex, ok := r.cache.Get(rKey.Src, rKey.Col)
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("%w: right %v", ErrIndexNotFound, rel.Right)
+	}
+	return lKey, lIndex, rKey, rIndex, nil
+}
+
+// joinResultChan sends the rows of seq to the channel until ctx is canceled.
+func joinResultChan(ctx context.Context, seq iter.Seq2[SelectItemList, error]) <-chan JoinResult {
+	resultC := make(chan JoinResult, 100)
 	go func() {
 		defer close(resultC)
-		lKey, rKey := rel.Left.Add(-1, -1), rel.Right.Add(-1, -1)
-		lIndex, ok := r.cache.Get(lKey.Src, lKey.Col)
-		if !ok {
-			logx.G().Warn("FullJoin: left index not found", logx.Any("key", lKey))
-			return
+		for row, err := range seq {
+			if !async.Send(ctx, resultC, NewJoinResult(row, err)) {
+				return
+			}
 		}
-		rIndex, ok := r.cache.Get(rKey.Src, rKey.Col)
-		if !ok {
-			logx.G().Warn("FullJoin: right index not found", logx.Any("key", rKey))
+	}()
+	return resultC
+}
+
+// joinResultSeq iterates the rows of the channel.
+func joinResultSeq(rowC <-chan JoinResult) iter.Seq2[SelectItemList, error] {
+	return func(yield func(SelectItemList, error) bool) {
+		for result := range rowC {
+			if !yield(result.Row(), result.Err()) {
+				return
+			}
+		}
+	}
+}
+
+func (r *relationJoiner) FullJoin(ctx context.Context, rel *joinkey.Relation) <-chan JoinResult {
+	return joinResultChan(ctx, r.FullJoinSeq(rel))
+}
+
+func (r *relationJoiner) Join(ctx context.Context, rel *joinkey.Relation, rowC <-chan JoinResult) <-chan JoinResult {
+	if rowC == nil {
+		return r.FullJoin(ctx, rel)
+	}
+	return joinResultChan(ctx, r.JoinSeq(rel, joinResultSeq(rowC)))
+}
+
+func (r *relationJoiner) FullJoinSeq(rel *joinkey.Relation) iter.Seq2[SelectItemList, error] {
+	return func(yield func(SelectItemList, error) bool) {
+		lKey, lIndex, rKey, rIndex, err := r.indexes(rel)
+		if err != nil {
+			yield(nil, fmt.Errorf("FullJoin: %w", err))
 			return
 		}
+		if r.optional[lKey.Src] && !r.optional[rKey.Src] {
+			// all rows of the required side are kept
+			lKey, lIndex, rKey, rIndex = rKey, rIndex, lKey, lIndex
+		}
 
 		// cross join for all items
-		for lItem := range lIndex.AllItems(ctx) {
-			rItemList, ok := rIndex.Get(lItem.Key())
-			if !ok {
+		for lItem := range lIndex.Items() {
+			if !r.filter.Pass(lKey.Src, lItem) {
 				continue
 			}
-			list := make(SelectItemList)
-			list.Set(NewSelectItem(lKey.Src, lItem))
+			rItemList, _ := rIndex.Get(lItem.Key())
+			var (
+				list  = make(SelectItemList)
+				found bool
+			)
+			list.Set(newRowItem(lKey.Src, lKey.Col, lItem))
 			for _, rItem := range rItemList {
-				if async.Done(ctx) {
-					return
+				if !r.filter.Pass(rKey.Src, rItem) {
+					continue
 				}
+				found = true
 				l := list.Clone()
-				l.Set(NewSelectItem(rKey.Src, rItem))
-				logx.G().Debug("FullJoin", logx.Any("left", lKey), logx.Any("right", rKey), logx.Any("list", l))
-				resultC <- l
+				l.Set(newRowItem(rKey.Src, rKey.Col, rItem))
+				logx.C(logx.Join).Debug("FullJoin", logx.Any("left", lKey), logx.Any("right", rKey), logx.Any("list", l))
+				if !yield(l, nil) {
+					return
+				}
+			}
+			if found || !r.optional[rKey.Src] {
+				continue
+			}
+			if !yield(r.withNull(list, rKey.Src, rIndex)) {
+				return
 			}
 		}
-	}()
-	return resultC
+	}
 }
 
-func (r *relationJoiner) Join(ctx context.Context, rel *joinkey.Relation, rowC <-chan SelectItemList) <-chan SelectItemList {
-	if rowC == nil {
-		return r.FullJoin(ctx, rel)
+// rowItem is a SelectItem that remembers the column it was found by and the fields of its line,
+// so that a line is read and split at most once per joined row.
+// The rows cloned from a row share the rowItem.
+type rowItem struct {
+	source int
+	col    int
+	item   Item
+	fields atomic.Pointer[[]string]
+}
+
+func newRowItem(src, col int, item Item) *rowItem {
+	return &rowItem{
+		source: src,
+		col:    col,
+		item:   item,
 	}
+}
 
-	resultC := make(chan SelectItemList, 100)
-	go func() {
-		defer close(resultC)
+func (r *rowItem) Source() int { return r.source }
+func (r *rowItem) Item() Item  { return r.item }
+func (r *rowItem) String() string {
+	return fmt.Sprintf("RowItem(%d, %d, %+v)", r.source, r.col, r.item)
+}
 
-		lKey, rKey := rel.Left.Add(-1, -1), rel.Right.Add(-1, -1) // into zero-based
-		lIndex, ok := r.cache.Get(lKey.Src, lKey.Col)
-		if !ok {
-			logx.G().Warn("Join: left index not found", logx.Any("key", lKey))
-			return
+// readFields returns the fields of the line of the item.
+// The line is read by idx unless the item already has the fields.
+func readFields(idx Index, x SelectItem) ([]string, error) {
+	if null, ok := x.(*nullRow); ok {
+		return null.fields(), nil
+	}
+	r, ok := x.(*rowItem)
+	if ok {
+		if fields := r.fields.Load(); fields != nil {
+			return *fields, nil
 		}
-		rIndex, ok := r.cache.Get(rKey.Src, rKey.Col)
-		if !ok {
-			logx.G().Warn("Join: right index not found", logx.Any("key", rKey))
+	}
+	scanned, err := idx.Read(x.Item())
+	if err != nil {
+		return nil, fmt.Errorf("read %w", err)
+	}
+	fields, err := idx.Split(scanned.Line())
+	if err != nil {
+		return nil, fmt.Errorf("split %w", err)
+	}
+	if ok {
+		r.fields.Store(&fields)
+	}
+	return fields, nil
+}
+
+// readKey returns the key of the item at the zero-based column.
+// The key of the column the item was found by is known without reading the line.
+func readKey(idx Index, x SelectItem, col int) (string, error) {
+	if r, ok := x.(*rowItem); ok && r.col == col {
+		return r.item.Key(), nil
+	}
+	fields, err := readFields(idx, x)
+	if err != nil {
+		return "", err
+	}
+	if col < 0 || col >= len(fields) {
+		return "", fmt.Errorf("key %w col %d fields %v", ErrNewKeyFailure, col, fields)
+	}
+	return fields[col], nil
+}
+
+func (r *relationJoiner) JoinSeq(rel *joinkey.Relation, rows iter.Seq2[SelectItemList, error]) iter.Seq2[SelectItemList, error] {
+	if rows == nil {
+		return r.FullJoinSeq(rel)
+	}
+
+	return func(yield func(SelectItemList, error) bool) {
+		lKey, lIndex, rKey, rIndex, err := r.indexes(rel)
+		if err != nil {
+			yield(nil, fmt.Errorf("Join: %w", err))
 			return
 		}
 
@@ -130,19 +284,25 @@ func (r *relationJoiner) Join(ctx context.Context, rel *joinkey.Relation, rowC <
 			isTop   = true
 			sources []int
 		)
-		for row := range rowC {
-			if async.Done(ctx) {
-				return
+		for row, err := range rows {
+			if err != nil {
+				if !yield(nil, err) {
+					return
+				}
+				continue
 			}
-			baseInfo := fmt.Sprintf("lkey %v rkey %v row %v", lKey, rKey, row)
-			logx.G().Debug("Join check", logx.S("info", baseInfo))
+
+			info := func() string {
+				return fmt.Sprintf("lkey %v rkey %v row %v", lKey, rKey, row)
+			}
+			logx.C(logx.Join).Debug("Join check", logx.Any("left", lKey), logx.Any("right", rKey), logx.Any("row", row))
 
 			if isTop {
 				isTop = false
 				sources = row.Keys()
 			} else if !slices.Equal(row.Keys(), sources) {
 				// all rows should consist of the same sources
-				logx.G().Error("Join: Inconsistent rows", logx.II("want", sources), logx.II("got", row.Keys()), logx.S("info", baseInfo))
+				yield(nil, fmt.Errorf("Join: %w want %v got %v %s", ErrInconsistentRows, sources, row.Keys(), info()))
 				return
 			}
 
@@ -151,103 +311,129 @@ func (r *relationJoiner) Join(ctx context.Context, rel *joinkey.Relation, rowC <
 			rRow, rExist := row[rKey.Src]
 			switch {
 			case lExist && !rExist:
-				lScanned, err := lIndex.Read(lRow.Item())
-				if err != nil {
-					logx.G().Debug("Join: left read", logx.Err(err), logx.S("info", baseInfo))
-					continue
-				}
-				key, err := lIndex.KeyFunc()(lScanned.Line())
-				if err != nil {
-					logx.G().Debug("Join: left key", logx.Err(err), logx.S("info", baseInfo))
-					continue
-				}
-				rItemList, ok := rIndex.Get(key)
-				if !ok {
-					continue
+				var (
+					rItemList []Item
+					key       string
+				)
+				if !IsNull(lRow) {
+					key, err = readKey(lIndex, lRow, lKey.Col)
+					if err != nil {
+						if !yield(nil, fmt.Errorf("Join: left %w %s", err, info())) {
+							return
+						}
+						continue
+					}
+					rItemList, _ = rIndex.Get(key)
 				}
+				var found bool
 				for _, rItem := range rItemList {
+					if !r.filter.Pass(rKey.Src, rItem) {
+						continue
+					}
+					found = true
 					l := row.Clone()
-					l.Set(NewSelectItem(rKey.Src, rItem))
-					logx.G().Debug("Join: from left",
-						logx.Group("left", logx.Any("row", lRow), logx.S("line", lScanned.Line())),
+					l.Set(newRowItem(rKey.Src, rKey.Col, rItem))
+					logx.C(logx.Join).Debug("Join: from left",
+						logx.Group("left", logx.Any("row", lRow)),
 						logx.S("key", key),
 						logx.Group("right", logx.Any("item", rItem)),
-						logx.S("info", baseInfo),
 					)
-					resultC <- l
+					if !yield(l, nil) {
+						return
+					}
 				}
-			case !lExist && rExist:
-				rScanned, err := rIndex.Read(rRow.Item())
-				if err != nil {
-					logx.G().Debug("Join: right read", logx.Err(err), logx.S("info", baseInfo))
-					continue
+				if !found && r.optional[rKey.Src] {
+					if !yield(r.withNull(row, rKey.Src, rIndex)) {
+						return
+					}
 				}
-				key, err := rIndex.KeyFunc()(rScanned.Line())
-				if err != nil {
-					logx.G().Debug("Join: right key", logx.Err(err), logx.S("info", baseInfo))
-					continue
-				}
-				lItemList, ok := lIndex.Get(key)
-				if !ok {
-					continue
+			case !lExist && rExist:
+				var (
+					lItemList []Item
+					key       string
+				)
+				if !IsNull(rRow) {
+					key, err = readKey(rIndex, rRow, rKey.Col)
+					if err != nil {
+						if !yield(nil, fmt.Errorf("Join: right %w %s", err, info())) {
+							return
+						}
+						continue
+					}
+					lItemList, _ = lIndex.Get(key)
 				}
+				var found bool
 				for _, lItem := range lItemList {
+					if !r.filter.Pass(lKey.Src, lItem) {
+						continue
+					}
+					found = true
 					l := row.Clone()
-					l.Set(NewSelectItem(lKey.Src, lItem))
-					logx.G().Debug("Join: from right",
-						logx.Group("right", logx.Any("row", rRow), logx.S("line", rScanned.Line())),
+					l.Set(newRowItem(lKey.Src, lKey.Col, lItem))
+					logx.C(logx.Join).Debug("Join: from right",
+						logx.Group("right", logx.Any("row", rRow)),
 						logx.S("key", key),
 						logx.Group("left", logx.Any("item", lItem)),
-						logx.S("info", baseInfo),
 					)
-					resultC <- l
+					if !yield(l, nil) {
+						return
+					}
 				}
-			case lExist && rExist:
-				lScanned, err := lIndex.Read(lRow.Item())
-				if err != nil {
-					logx.G().Debug("Join: row left read", logx.Err(err), logx.S("info", baseInfo))
-					continue
+				if !found && r.optional[lKey.Src] {
+					if !yield(r.withNull(row, lKey.Src, lIndex)) {
+						return
+					}
 				}
-				lk, err := lIndex.KeyFunc()(lScanned.Line())
-				if err != nil {
-					logx.G().Debug("Join: row left key", logx.Err(err), logx.S("info", baseInfo))
+			case lExist && rExist:
+				if IsNull(lRow) || IsNull(rRow) {
+					// no keys to be equal
 					continue
 				}
-				rScanned, err := rIndex.Read(rRow.Item())
+				lk, err := readKey(lIndex, lRow, lKey.Col)
 				if err != nil {
-					logx.G().Debug("Join: row right read", logx.Err(err), logx.S("info", baseInfo))
+					if !yield(nil, fmt.Errorf("Join: row left %w %s", err, info())) {
+						return
+					}
 					continue
 				}
-				rk, err := rIndex.KeyFunc()(rScanned.Line())
+				rk, err := readKey(rIndex, rRow, rKey.Col)
 				if err != nil {
-					logx.G().Debug("Join: row right key", logx.Err(err), logx.S("info", baseInfo))
+					if !yield(nil, fmt.Errorf("Join: row right %w %s", err, info())) {
+						return
+					}
 					continue
 				}
-				logx.G().Debug("Join: row",
+				logx.C(logx.Join).Debug("Join: row",
 					logx.Group("left",
 						logx.Any("row", lRow),
-						logx.S("line", lScanned.Line()),
 						logx.S("key", lk),
 					),
 					logx.Group("right",
 						logx.Any("row", rRow),
-						logx.S("line", rScanned.Line()),
 						logx.S("key", rk),
 					),
 				)
 				if lk == rk {
-					resultC <- row
+					if !yield(row, nil) {
+						return
+					}
 				}
 			default:
-				logx.G().Warn("Join: no rows found", logx.S("info", baseInfo))
+				// the relation is disconnected from the preceding relations
+				yield(nil, fmt.Errorf("Join: %w %s", ErrNoRowsFound, info()))
+				return
 			}
 		}
-	}()
-	return resultC
+	}
 }
 
 type Joiner interface {
-	Join(ctx context.Context, key *joinkey.JoinKey) <-chan SelectItemList
+	// Join joins the sources by the key.
+	// A result with an error means that some rows are lost.
+	Join(ctx context.Context, key *joinkey.JoinKey) <-chan JoinResult
+	// JoinSeq is the iterator version of Join.
+	// This runs on the caller's goroutine, breaking the loop stops the join.
+	JoinSeq(key *joinkey.JoinKey) iter.Seq2[SelectItemList, error]
 }
 
 func New(relJoiner RelationJoiner) Joiner {
@@ -260,17 +446,20 @@ type joinerImpl struct {
 	relJoiner RelationJoiner
 }
 
-func (j *joinerImpl) Join(ctx context.Context, key *joinkey.JoinKey) <-chan SelectItemList {
+func (j *joinerImpl) Join(ctx context.Context, key *joinkey.JoinKey) <-chan JoinResult {
+	return joinResultChan(ctx, j.JoinSeq(key))
+}
+
+func (j *joinerImpl) JoinSeq(key *joinkey.JoinKey) iter.Seq2[SelectItemList, error] {
 	if len(key.RelationList) == 0 {
-		logx.G().Error("Joiner: empty key")
-		resultC := make(chan SelectItemList)
-		close(resultC)
-		return resultC
+		return func(yield func(SelectItemList, error) bool) {
+			yield(nil, fmt.Errorf("Joiner: %w", ErrEmptyKey))
+		}
 	}
 
-	var resultC <-chan SelectItemList
+	var rows iter.Seq2[SelectItemList, error]
 	for _, k := range key.RelationList {
-		resultC = j.relJoiner.Join(ctx, k, resultC)
+		rows = j.relJoiner.JoinSeq(k, rows)
 	}
-	return resultC
+	return rows
 }
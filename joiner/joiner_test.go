@@ -2,6 +2,7 @@ package joiner_test
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"sort"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/berquerant/joiny/cc/target"
 	"github.com/berquerant/joiny/joiner"
 	"github.com/berquerant/joiny/logx"
+	"github.com/berquerant/joiny/source"
 	"github.com/berquerant/joiny/temporary"
 	"github.com/stretchr/testify/assert"
 )
@@ -159,7 +161,10 @@ func TestJoiner(t *testing.T) {
 				s := joiner.NewSelector(cache)
 				got := []string{}
 				for x := range j.FullJoin(context.TODO(), tc.rel) {
-					v, err := s.Select(tc.tgt, x.Sorted())
+					if err := x.Err(); err != nil {
+						t.Fatal(err)
+					}
+					v, err := s.Select(tc.tgt, x.Row().Sorted())
 					if err != nil {
 						t.Fatal(err)
 					}
@@ -332,18 +337,317 @@ func TestJoiner(t *testing.T) {
 
 				s := joiner.NewSelector(cache)
 				j := joiner.New(joiner.NewRelationJoiner(cache))
+				sort.Strings(tc.want)
+				for _, key := range []*joinkey.JoinKey{tc.key, joiner.Reorder(tc.key, cache)} {
+					got := []string{}
+					for x := range j.Join(context.TODO(), key) {
+						if err := x.Err(); err != nil {
+							t.Fatal(err)
+						}
+						v, err := s.Select(tc.tgt, x.Row().Sorted())
+						if err != nil {
+							t.Fatal(err)
+						}
+						got = append(got, v)
+					}
+					sort.Strings(got)
+					assert.Equal(t, tc.want, got, "key %s", key.Expr())
+
+					gotSeq := []string{}
+					for row, err := range j.JoinSeq(key) {
+						if err != nil {
+							t.Fatal(err)
+						}
+						v, err := s.Select(tc.tgt, row.Sorted())
+						if err != nil {
+							t.Fatal(err)
+						}
+						gotSeq = append(gotSeq, v)
+					}
+					sort.Strings(gotSeq)
+					assert.Equal(t, tc.want, gotSeq, "seq key %s", key.Expr())
+				}
+			})
+		}
+	})
+
+	t.Run("optional sources", func(t *testing.T) {
+		for _, tc := range []struct {
+			title    string
+			rows     []string
+			key      *joinkey.JoinKey
+			optional []int
+			want     []string
+		}{
+			{
+				title: "left join",
+				rows: []string{
+					"1,a|a,A",
+					"2,b|c,C",
+					"3,a",
+				},
+				key: joinkey.NewJoinKey([]*joinkey.Relation{
+					joinkey.NewRelation(joinkey.NewLocation(1, 2), joinkey.NewLocation(2, 1)),
+				}),
+				optional: []int{1},
+				want: []string{
+					"1,a,a,A",
+					"2,b,,",
+					"3,a,a,A",
+				},
+			},
+			{
+				title: "left join then left join",
+				rows: []string{
+					"1,a|a,A|A,x",
+					"2,b|b,B|C,y",
+					"3,c",
+				},
+				key: joinkey.NewJoinKey([]*joinkey.Relation{
+					joinkey.NewRelation(joinkey.NewLocation(1, 2), joinkey.NewLocation(2, 1)),
+					joinkey.NewRelation(joinkey.NewLocation(2, 2), joinkey.NewLocation(3, 1)),
+				}),
+				optional: []int{1, 2},
+				want: []string{
+					"1,a,a,A,A,x",
+					"2,b,b,B,,",
+					"3,c,,,,",
+				},
+			},
+			{
+				title: "left join then join",
+				rows: []string{
+					"1,a|a,A|A,x",
+					"2,b|b,B|C,y",
+					"3,c",
+				},
+				key: joinkey.NewJoinKey([]*joinkey.Relation{
+					joinkey.NewRelation(joinkey.NewLocation(1, 2), joinkey.NewLocation(2, 1)),
+					joinkey.NewRelation(joinkey.NewLocation(2, 2), joinkey.NewLocation(3, 1)),
+				}),
+				optional: []int{1},
+				want: []string{
+					"1,a,a,A,A,x",
+				},
+			},
+		} {
+			t.Run(tc.title, func(t *testing.T) {
+				g := &multiSourceGenerator{}
+				for _, r := range tc.rows {
+					g.add(r)
+				}
+				g.generate()
+				defer g.close()
+
+				cache, err := joiner.NewCacheBuilder(
+					g.readSeekers(),
+					joiner.RelationListToLocationList(tc.key.RelationList),
+					",",
+					-1,
+					10,
+				).Build(context.TODO())
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				tgt := []target.Range{}
+				for i := range strings.Count(tc.rows[0], "|") + 1 {
+					tgt = append(tgt, target.NewLeft(target.NewLocation(i+1, 1)))
+				}
+				s := joiner.NewSelector(cache)
+				j := joiner.New(joiner.NewRelationJoiner(cache, joiner.WithOptionalSources(tc.optional...)))
 				got := []string{}
-				for x := range j.Join(context.TODO(), tc.key) {
-					v, err := s.Select(tc.tgt, x.Sorted())
+				for row, err := range j.JoinSeq(tc.key) {
+					if err != nil {
+						t.Fatal(err)
+					}
+					v, err := s.Select(target.NewTarget(tgt), row.Sorted())
 					if err != nil {
 						t.Fatal(err)
 					}
 					got = append(got, v)
 				}
 				sort.Strings(got)
-				sort.Strings(tc.want)
 				assert.Equal(t, tc.want, got)
 			})
 		}
 	})
+
+	t.Run("errors", func(t *testing.T) {
+		g := &multiSourceGenerator{}
+		g.add("a,b|a,c|c,d")
+		g.generate()
+		defer g.close()
+
+		// 3.1 is never connected to 1 and 2
+		key := joinkey.NewJoinKey([]*joinkey.Relation{
+			joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(2, 1)),
+			joinkey.NewRelation(joinkey.NewLocation(3, 1), joinkey.NewLocation(3, 1)),
+		})
+		cache, err := joiner.NewCacheBuilder(
+			g.readSeekers(),
+			joiner.RelationListToLocationList(key.RelationList),
+			",",
+			-1,
+			10,
+		).Build(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		j := joiner.New(joiner.NewRelationJoiner(cache))
+
+		for _, tc := range []struct {
+			title string
+			key   *joinkey.JoinKey
+			want  error
+		}{
+			{
+				title: "empty key",
+				key:   joinkey.NewJoinKey(nil),
+				want:  joiner.ErrEmptyKey,
+			},
+			{
+				title: "disconnected",
+				key:   key,
+				want:  joiner.ErrNoRowsFound,
+			},
+		} {
+			t.Run(tc.title, func(t *testing.T) {
+				var errs []error
+				for x := range j.Join(context.TODO(), tc.key) {
+					if err := x.Err(); err != nil {
+						errs = append(errs, err)
+					}
+				}
+				if assert.Equal(t, 1, len(errs)) {
+					assert.ErrorIs(t, errs[0], tc.want)
+				}
+			})
+		}
+	})
+
+	t.Run("seq break", func(t *testing.T) {
+		g := &multiSourceGenerator{}
+		g.add("a|a|a")
+		g.add("a|a|a")
+		g.generate()
+		defer g.close()
+
+		key := parseKey(t, "1.1=2.1,2.1=3.1")
+		cache, err := joiner.NewCacheBuilder(
+			g.readSeekers(),
+			joiner.RelationListToLocationList(key.RelationList),
+			",",
+			-1,
+			10,
+		).Build(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		for _, err := range joiner.New(joiner.NewRelationJoiner(cache)).JoinSeq(key) {
+			assert.Nil(t, err)
+			count++
+			if count == 3 {
+				break
+			}
+		}
+		assert.Equal(t, 3, count)
+	})
+}
+
+// chainSources returns n files of rows lines joined by "i.2=(i+1).1" and the key.
+func chainSources(tb testing.TB, n, rows int) ([]source.Source, *joinkey.JoinKey) {
+	tb.Helper()
+	var (
+		sources   = make([]source.Source, n)
+		relations = make([]*joinkey.Relation, n-1)
+	)
+	for i := range sources {
+		f, err := temporary.NewFile()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		tb.Cleanup(func() { f.Close() })
+		for j := 0; j < rows; j++ {
+			fmt.Fprintf(f, "k%d_%d,k%d_%d,%s\n", i, j, i+1, j, strings.Repeat("x", 40))
+		}
+		sources[i] = source.NewDelimited(f, ",")
+	}
+	for i := range relations {
+		relations[i] = joinkey.NewRelation(joinkey.NewLocation(i+1, 2), joinkey.NewLocation(i+2, 1))
+	}
+	return sources, joinkey.NewJoinKey(relations)
+}
+
+// joinReads joins and selects all rows, returns the number of rows and the line reads.
+func joinReads(tb testing.TB, cache joiner.Cache, key *joinkey.JoinKey, tgt *target.Target, sources int) (int, int64) {
+	tb.Helper()
+	var (
+		rows int
+		s    = joiner.NewSelector(cache)
+	)
+	for row, err := range joiner.New(joiner.NewRelationJoiner(cache)).JoinSeq(key) {
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := s.Select(tgt, row.Sorted()); err != nil {
+			tb.Fatal(err)
+		}
+		rows++
+	}
+	var reads int64
+	for i := 0; i < sources; i++ {
+		stats, _ := cache.ReadStats(i)
+		reads += stats.Hits + stats.Misses
+	}
+	return rows, reads
+}
+
+func allColumns(n int) *target.Target {
+	rs := make([]target.Range, n)
+	for i := range rs {
+		rs[i] = target.NewRight(target.NewLocation(i+1, 1))
+	}
+	return target.NewTarget(rs)
+}
+
+func TestJoinReadsLineOnce(t *testing.T) {
+	const (
+		n    = 4
+		rows = 20
+	)
+	sources, key := chainSources(t, n, rows)
+	cache, err := joiner.NewSourceCacheBuilder(
+		sources, joiner.RelationListToLocationList(key.RelationList), ",", 1, 1<<20,
+	).Build(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotRows, reads := joinReads(t, cache, key, allColumns(n), n)
+	assert.Equal(t, rows, gotRows)
+	assert.Equal(t, int64(n*rows), reads, "a line is read once per row")
+}
+
+func BenchmarkJoinSeq(b *testing.B) {
+	const rows = 20000
+	for _, n := range []int{3, 5} {
+		b.Run(fmt.Sprintf("%d-way", n), func(b *testing.B) {
+			sources, key := chainSources(b, n, rows)
+			cache, err := joiner.NewSourceCacheBuilder(
+				sources, joiner.RelationListToLocationList(key.RelationList), ",", 1, 1<<20,
+			).Build(context.TODO())
+			if err != nil {
+				b.Fatal(err)
+			}
+			tgt := allColumns(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			var reads int64
+			for i := 0; i < b.N; i++ {
+				_, reads = joinReads(b, cache, key, tgt, n)
+			}
+			b.ReportMetric(float64(reads)/float64(b.N)/rows, "reads/row")
+		})
+	}
 }
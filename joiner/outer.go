@@ -0,0 +1,99 @@
+package joiner
+
+import (
+	"fmt"
+	"sync"
+)
+
+// JoinOption configures RelationJoiner.
+type JoinOption func(*joinConfig)
+
+type joinConfig struct {
+	optional map[int]bool
+}
+
+// WithOptionalSources makes the sources (zero-based) optional like the right sides of LEFT JOIN.
+// A row that finds no partners in an optional source is kept with the null row of the source, see IsNull.
+// The relation that adds an optional source to the rows should be the only relation between the source and the preceding sources,
+// the relations should not be reordered.
+// Prefilter is ignored because the rows without partners are kept.
+func WithOptionalSources(srcs ...int) JoinOption {
+	return func(c *joinConfig) {
+		for _, src := range srcs {
+			c.optional[src] = true
+		}
+	}
+}
+
+func newJoinConfig(opt ...JoinOption) *joinConfig {
+	c := &joinConfig{
+		optional: make(map[int]bool),
+	}
+	for _, f := range opt {
+		f(c)
+	}
+	return c
+}
+
+// nullRow is the row of an optional source that has no partners.
+// The fields are empty, the number of them is that of the first line of the source.
+type nullRow struct {
+	source int
+	width  int
+}
+
+// nullItem is the item of the null rows, not a line of the source.
+var nullItem Item = NewItem("", -1, 0)
+
+func (n *nullRow) Source() int    { return n.source }
+func (n *nullRow) Item() Item     { return nullItem }
+func (n *nullRow) String() string { return fmt.Sprintf("NullRow(%d, %d)", n.source, n.width) }
+func (n *nullRow) fields() []string {
+	return make([]string, n.width)
+}
+
+// IsNull returns true if the item is the null row of an optional source, see WithOptionalSources.
+// The null rows have no keys, the relations with them are never satisfied.
+func IsNull(x SelectItem) bool {
+	_, ok := x.(*nullRow)
+	return ok
+}
+
+// nullRows makes the null rows of the sources.
+type nullRows struct {
+	mux  sync.Mutex
+	rows map[int]*nullRow
+}
+
+func newNullRows() *nullRows {
+	return &nullRows{
+		rows: make(map[int]*nullRow),
+	}
+}
+
+// get returns the null row of the source of idx.
+func (n *nullRows) get(src int, idx Index) (*nullRow, error) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	if r, ok := n.rows[src]; ok {
+		return r, nil
+	}
+	r := &nullRow{
+		source: src,
+		width:  1,
+	}
+	for x := range idx.Items() {
+		scanned, err := idx.Read(x)
+		if err != nil {
+			return nil, fmt.Errorf("null row: %w", err)
+		}
+		fields, err := idx.Split(scanned.Line())
+		if err != nil {
+			return nil, fmt.Errorf("null row: %w", err)
+		}
+		r.width = max(r.width, len(fields))
+		break
+	}
+	n.rows[src] = r
+	return r, nil
+}
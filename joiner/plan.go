@@ -0,0 +1,305 @@
+package joiner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/berquerant/joiny/slicing"
+)
+
+type StepKind int
+
+const (
+	// StepFullJoin scans the left index and looks up the right index.
+	StepFullJoin StepKind = iota
+	// StepFromLeft reads the left source of the row and looks up the right index.
+	StepFromLeft
+	// StepFromRight reads the right source of the row and looks up the left index.
+	StepFromRight
+	// StepFilter reads both sources of the row and compares the keys.
+	StepFilter
+	// StepDisconnected means that the row contains neither source, yields no rows.
+	StepDisconnected
+)
+
+func (k StepKind) String() string {
+	switch k {
+	case StepFullJoin:
+		return "full join"
+	case StepFromLeft:
+		return "join from left"
+	case StepFromRight:
+		return "join from right"
+	case StepFilter:
+		return "filter"
+	case StepDisconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// Step is a stage of the join chain.
+type Step struct {
+	Relation *joinkey.Relation
+	Kind     StepKind
+}
+
+// Chain returns the stages in order that Joiner applies the relations.
+func Chain(key *joinkey.JoinKey) []*Step {
+	var (
+		r      = make([]*Step, len(key.RelationList))
+		joined = make(map[int]bool)
+	)
+	for i, rel := range key.RelationList {
+		var (
+			lSrc, rSrc = rel.Left.Src, rel.Right.Src
+			kind       StepKind
+		)
+		switch {
+		case i == 0:
+			kind = StepFullJoin
+		case joined[lSrc] && !joined[rSrc]:
+			kind = StepFromLeft
+		case !joined[lSrc] && joined[rSrc]:
+			kind = StepFromRight
+		case joined[lSrc] && joined[rSrc]:
+			kind = StepFilter
+		default:
+			kind = StepDisconnected
+		}
+		if kind != StepDisconnected {
+			joined[lSrc] = true
+			joined[rSrc] = true
+		}
+		r[i] = &Step{
+			Relation: rel,
+			Kind:     kind,
+		}
+	}
+	return r
+}
+
+// SourceSample is the statistics from the head of the source.
+type SourceSample struct {
+	// Size is the bytes of the source.
+	Size int64
+	// Exact is true if the sample covers the whole source.
+	Exact       bool
+	SampleRows  int
+	SampleBytes int64
+	// Columns is the number of the columns of the first row.
+	Columns int
+	// Distinct is the number of the distinct keys in the sample for each zero-based column.
+	Distinct map[int]int
+}
+
+// Rows returns the estimated number of the rows.
+func (s *SourceSample) Rows() int {
+	if s.Exact || s.SampleBytes == 0 {
+		return s.SampleRows
+	}
+	return int(float64(s.Size) / float64(s.SampleBytes) * float64(s.SampleRows))
+}
+
+// DistinctKeys returns the estimated number of the distinct keys of the zero-based column.
+func (s *SourceSample) DistinctKeys(col int) int {
+	d := s.Distinct[col]
+	if s.Exact || s.SampleRows == 0 {
+		return d
+	}
+	return int(float64(d) / float64(s.SampleRows) * float64(s.Rows()))
+}
+
+// NewSourceSample reads the head of the data up to limit bytes, counts the rows and the distinct keys of cols.
+// cols are zero-based.
+func NewSourceSample(data io.ReadSeeker, delimiter string, cols []int, limit int64) (*SourceSample, error) {
+	size, err := data.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("Sample: %w", err)
+	}
+	if _, err := data.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("Sample: %w", err)
+	}
+
+	var (
+		s = &SourceSample{
+			Size:     size,
+			Exact:    size <= limit,
+			Distinct: make(map[int]int, len(cols)),
+		}
+		keys = make([]map[string]bool, len(cols))
+		r    = bufio.NewReader(io.LimitReader(data, limit))
+	)
+	for i := range keys {
+		keys[i] = make(map[string]bool)
+	}
+	for {
+		line, err := r.ReadString('\n')
+		isEOF := errors.Is(err, io.EOF)
+		if err != nil && !isEOF {
+			return nil, fmt.Errorf("Sample: %w", err)
+		}
+		if isEOF && !s.Exact {
+			break // ignore the cut line
+		}
+		lineStr := strings.TrimRight(line, "\n")
+		if lineStr != "" {
+			ss := strings.Split(lineStr, delimiter)
+			if s.SampleRows == 0 {
+				s.Columns = len(ss)
+			}
+			for i, col := range cols {
+				if slicing.InRange(ss, col) {
+					keys[i][ss[col]] = true
+				}
+			}
+			s.SampleRows++
+		}
+		s.SampleBytes += int64(len(line))
+		if isEOF {
+			break
+		}
+	}
+	for i, col := range cols {
+		s.Distinct[col] = len(keys[i])
+	}
+	return s, nil
+}
+
+// PlanStep is the stage of the join chain with the estimated number of the output rows.
+type PlanStep struct {
+	*Step
+	Rows int
+}
+
+// Plan describes what Joiner will do.
+type Plan struct {
+	Key *joinkey.JoinKey
+	// Reordered is the key to be joined actually, nil if not reordered.
+	Reordered *joinkey.JoinKey
+	// Indexes are the zero-based columns to be indexed for each zero-based source.
+	Indexes map[int][]int
+	Samples []*SourceSample
+	Steps   []*PlanStep
+	// Target is the resolved columns like "1.2", assuming that all rows have the same columns as the first row.
+	Target []string
+}
+
+// Explain makes the join plan from the heads of the sources.
+// If reorder is true then the relations are reordered by Reorder with the statistics from the heads.
+func Explain(dataList []io.ReadSeeker, key *joinkey.JoinKey, tgt *target.Target, delimiter string, sampleSize int64, reorder bool) (*Plan, error) {
+	indexes := IndexLocations(RelationListToLocationList(key.RelationList))
+	samples := make([]*SourceSample, len(dataList))
+	for i, data := range dataList {
+		s, err := NewSourceSample(data, delimiter, indexes[i], sampleSize)
+		if err != nil {
+			return nil, fmt.Errorf("Explain: source %d %w", i+1, err)
+		}
+		samples[i] = s
+	}
+	for src := range indexes {
+		if !slicing.InRange(samples, src) {
+			return nil, fmt.Errorf("Explain: %w source %d, source len %d", ErrInvalidKey, src+1, len(samples))
+		}
+	}
+
+	columns := make([][]string, len(samples))
+	for i, s := range samples {
+		columns[i] = make([]string, s.Columns)
+		for j := range columns[i] {
+			columns[i][j] = fmt.Sprintf("%d.%d", i+1, j+1)
+		}
+	}
+	resolved, err := SelectColumnsByTarget(tgt, columns)
+	if err != nil {
+		return nil, fmt.Errorf("Explain: %w", err)
+	}
+
+	var (
+		stats     = NewSampleStatistics(samples)
+		chainKey  = key
+		reordered *joinkey.JoinKey
+	)
+	if reorder {
+		reordered = Reorder(key, stats)
+		chainKey = reordered
+	}
+	return &Plan{
+		Key:       key,
+		Reordered: reordered,
+		Indexes:   indexes,
+		Samples:   samples,
+		Steps:     estimateSteps(Chain(chainKey), stats),
+		Target:    resolved,
+	}, nil
+}
+
+// estimateSteps estimates the output rows of the steps by |L||R|/max(distinct(L), distinct(R)).
+func estimateSteps(steps []*Step, stats Statistics) []*PlanStep {
+	var (
+		result = make([]*PlanStep, len(steps))
+		rows   float64
+	)
+	for i, step := range steps {
+		l, r := relationStats(stats, step.Relation)
+		switch step.Kind {
+		case StepFullJoin:
+			rows = float64(l.Rows) * float64(r.Rows) * selectivity(l, r)
+		case StepFromLeft:
+			rows *= float64(r.Rows) * selectivity(l, r)
+		case StepFromRight:
+			rows *= float64(l.Rows) * selectivity(l, r)
+		case StepFilter:
+			if *step.Relation.Left != *step.Relation.Right {
+				rows *= selectivity(l, r)
+			}
+		default:
+			rows = 0
+		}
+		result[i] = &PlanStep{
+			Step: step,
+			Rows: int(rows),
+		}
+	}
+	return result
+}
+
+func (p *Plan) String() string {
+	var (
+		b     strings.Builder
+		write = func(format string, v ...any) {
+			b.WriteString(fmt.Sprintf(format, v...))
+		}
+	)
+
+	write("key: %s\n", p.Key.Expr())
+	if p.Reordered != nil {
+		write("reordered: %s\n", p.Reordered.Expr())
+	}
+
+	write("sources:\n")
+	for i, s := range p.Samples {
+		approx := "~"
+		if s.Exact {
+			approx = ""
+		}
+		write("  %d: bytes %d, rows %s%d, columns %d\n", i+1, s.Size, approx, s.Rows(), s.Columns)
+		for _, col := range p.Indexes[i] {
+			write("    index %d.%d: distinct keys %s%d\n", i+1, col+1, approx, s.DistinctKeys(col))
+		}
+	}
+
+	write("chain:\n")
+	for i, step := range p.Steps {
+		write("  %d: %s %s, rows ~%d\n", i+1, step.Relation.Expr(), step.Kind, step.Rows)
+	}
+
+	write("target: %s\n", strings.Join(p.Target, ","))
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package joiner_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	rel := func(ls, lc, rs, rc int) *joinkey.Relation {
+		return joinkey.NewRelation(joinkey.NewLocation(ls, lc), joinkey.NewLocation(rs, rc))
+	}
+	key := joinkey.NewJoinKey([]*joinkey.Relation{
+		rel(1, 1, 2, 1),
+		rel(2, 2, 3, 1),
+		rel(4, 1, 1, 2),
+		rel(3, 2, 1, 3),
+		rel(5, 1, 6, 1),
+	})
+	got := joiner.Chain(key)
+	want := []joiner.StepKind{
+		joiner.StepFullJoin,
+		joiner.StepFromLeft,
+		joiner.StepFromRight,
+		joiner.StepFilter,
+		joiner.StepDisconnected,
+	}
+	assert.Equal(t, len(want), len(got))
+	for i, w := range want {
+		assert.Equal(t, w, got[i].Kind, "step %d", i)
+		assert.Equal(t, key.RelationList[i], got[i].Relation)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	g := &multiSourceGenerator{}
+	for _, r := range []string{
+		"11,12,13|11,a",
+		"21,22,23|11,b",
+		"31,32,33|21,c",
+		"41,42,43|51,d",
+	} {
+		g.add(r)
+	}
+	g.generate()
+	defer g.close()
+
+	key := joinkey.NewJoinKey([]*joinkey.Relation{
+		joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(2, 1)),
+	})
+	tgt := target.NewTarget([]target.Range{
+		target.NewRight(target.NewLocation(1, 2)),
+		target.NewLeft(target.NewLocation(2, 2)),
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		plan, err := joiner.Explain(g.readSeekers(), key, tgt, ",", 1024, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, map[int][]int{0: {0}, 1: {0}}, plan.Indexes)
+		assert.Equal(t, []string{"1.1", "1.2", "2.2"}, plan.Target)
+		assert.Equal(t, 2, len(plan.Samples))
+		for _, s := range plan.Samples {
+			assert.True(t, s.Exact)
+			assert.Equal(t, 4, s.Rows())
+		}
+		assert.Equal(t, 4, plan.Samples[0].DistinctKeys(0))
+		assert.Equal(t, 3, plan.Samples[1].DistinctKeys(0))
+		assert.Equal(t, 1, len(plan.Steps))
+		assert.Equal(t, 4, plan.Steps[0].Rows)
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		plan, err := joiner.Explain(g.readSeekers(), key, tgt, ",", 20, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := plan.Samples[0]
+		assert.False(t, s.Exact)
+		assert.Equal(t, 2, s.SampleRows)
+		assert.Equal(t, 4, s.Rows())
+	})
+
+	t.Run("source out of range", func(t *testing.T) {
+		_, err := joiner.Explain(g.readSeekers(), joinkey.NewJoinKey([]*joinkey.Relation{
+			joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(3, 1)),
+		}), tgt, ",", 1024, false)
+		assert.ErrorIs(t, err, joiner.ErrInvalidKey)
+	})
+}
@@ -0,0 +1,122 @@
+package joiner
+
+import (
+	"github.com/berquerant/joiny/logx"
+	"golang.org/x/exp/slices"
+)
+
+// KeyFilter tests whether a key is in the keys of an index.
+type KeyFilter interface {
+	Contains(key string) bool
+}
+
+// NewKeyFilter returns a filter of the keys of the index.
+// The indexes are in memory, the keys are looked up directly.
+func NewKeyFilter(idx Index) KeyFilter {
+	if x, ok := idx.(*index); ok {
+		return &storeFilter{store: x.val}
+	}
+	return &indexFilter{idx: idx}
+}
+
+type indexFilter struct {
+	idx Index
+}
+
+func (f *indexFilter) Contains(key string) bool {
+	_, ok := f.idx.Get(key)
+	return ok
+}
+
+type storeFilter struct {
+	store itemStore
+}
+
+func (f *storeFilter) Contains(key string) bool { return f.store.has(key) }
+
+// Prefilter drops the rows that cannot be joined before reading them.
+type Prefilter interface {
+	// Pass returns false if the item of the zero-based source cannot satisfy some relation.
+	Pass(src int, item Item) bool
+}
+
+type passAll struct{}
+
+func (passAll) Pass(_ int, _ Item) bool { return true }
+
+// prefilter holds the sorted offsets of the rows that cannot be joined for each source.
+type prefilter struct {
+	dead map[int][]int64
+}
+
+func (p *prefilter) Pass(src int, item Item) bool {
+	_, found := slices.BinarySearch(p.dead[src], item.Offset())
+	return !found
+}
+
+// newPrefilter finds the rows whose keys are not in the other sides of the relations.
+// locationList is the pairs of the relations, see RelationListToLocationList.
+// The relations within a source are ignored.
+func newPrefilter(c *cache, locationList []Location) *prefilter {
+	var (
+		filters = make(map[cacheKey]KeyFilter)
+		filter  = func(loc Location) (KeyFilter, bool) {
+			k := cacheKey{
+				src: loc.Source(),
+				col: loc.Column(),
+			}
+			if f, ok := filters[k]; ok {
+				return f, true
+			}
+			idx, ok := c.Get(k.src, k.col)
+			if !ok {
+				return nil, false
+			}
+			f := NewKeyFilter(idx)
+			filters[k] = f
+			return f, true
+		}
+		dead = make(map[int][]int64)
+		drop = func(loc, other Location) {
+			idx, ok := c.Get(loc.Source(), loc.Column())
+			if !ok {
+				return
+			}
+			x, ok := idx.(*index)
+			if !ok {
+				return
+			}
+			f, ok := filter(other)
+			if !ok {
+				return
+			}
+			for k := range x.val.allKeys() {
+				if f.Contains(k) {
+					continue
+				}
+				items, _ := x.val.get(k)
+				for _, item := range items {
+					dead[loc.Source()] = append(dead[loc.Source()], item.Offset())
+				}
+			}
+		}
+	)
+
+	for i := 0; i+1 < len(locationList); i += 2 {
+		l, r := locationList[i], locationList[i+1]
+		if l.Source() == r.Source() {
+			continue
+		}
+		drop(l, r)
+		drop(r, l)
+	}
+	for src, offsets := range dead {
+		slices.Sort(offsets)
+		offsets = slices.Compact(offsets)
+		dead[src] = offsets
+		logx.C(logx.Index).Debug("Prefilter", logx.I("source", src+1), logx.I("dropped_rows", len(offsets)))
+	}
+	return &prefilter{
+		dead: dead,
+	}
+}
@@ -0,0 +1,97 @@
+package joiner_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyFilter(t *testing.T) {
+	const keys = 1000
+	for _, layout := range []joiner.IndexLayout{joiner.LayoutCompact, joiner.LayoutMap} {
+		t.Run(layout.String(), func(t *testing.T) {
+			var sb strings.Builder
+			for i := 0; i < keys; i++ {
+				fmt.Fprintf(&sb, "in%d,v\n", i)
+			}
+			f := joiner.NewKeyFilter(loadLayout(t, sb.String(), layout))
+
+			for i := 0; i < keys; i++ {
+				assert.True(t, f.Contains(fmt.Sprintf("in%d", i)), "in%d", i)
+				assert.False(t, f.Contains(fmt.Sprintf("out%d", i)), "out%d", i)
+			}
+		})
+	}
+}
+
+func TestPrefilter(t *testing.T) {
+	const rows = 100
+	var s1, s2, s3 strings.Builder
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&s1, "k%d,a%d\n", i, i)
+		fmt.Fprintf(&s2, "k%d,j%d\n", i, i)
+		if i%10 == 0 {
+			fmt.Fprintf(&s3, "j%d,z%d\n", i, i)
+		}
+	}
+	var (
+		key = joinkey.NewJoinKey([]*joinkey.Relation{
+			joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(2, 1)),
+			joinkey.NewRelation(joinkey.NewLocation(2, 2), joinkey.NewLocation(3, 1)),
+		})
+		tgt = target.NewTarget([]target.Range{
+			target.NewRight(target.NewLocation(1, 1)),
+			target.NewRight(target.NewLocation(2, 1)),
+			target.NewRight(target.NewLocation(3, 1)),
+		})
+	)
+
+	join := func(t *testing.T, enabled bool) ([]string, int64) {
+		t.Helper()
+		cache, err := joiner.NewSourceCacheBuilder(
+			[]source.Source{
+				source.NewDelimited(strings.NewReader(s1.String()), ","),
+				source.NewDelimited(strings.NewReader(s2.String()), ","),
+				source.NewDelimited(strings.NewReader(s3.String()), ","),
+			},
+			joiner.RelationListToLocationList(key.RelationList),
+			",", 1, 1<<20,
+			joiner.WithPrefilter(enabled),
+		).Build(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			got []string
+			s   = joiner.NewSelector(cache)
+		)
+		for row, err := range joiner.New(joiner.NewRelationJoiner(cache)).JoinSeq(key) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := s.Select(tgt, row.Sorted())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, v)
+		}
+		sort.Strings(got)
+		stats, _ := cache.ReadStats(1)
+		return got, stats.Misses
+	}
+
+	want, wantMisses := join(t, false)
+	got, gotMisses := join(t, true)
+	assert.Equal(t, want, got)
+	assert.Len(t, got, rows/10)
+	assert.Equal(t, int64(rows), wantMisses, "all rows of source 2 are read")
+	assert.Equal(t, int64(rows/10), gotMisses, "only the rows of source 2 that can be joined are read")
+}
@@ -0,0 +1,70 @@
+package joiner
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// LoadProgress counts the records of a source loaded by IndexLoader.
+// This is safe for concurrent use.
+type LoadProgress struct {
+	bytes atomic.Int64
+	lines atomic.Int64
+	total atomic.Int64
+	done  atomic.Bool
+}
+
+// Bytes returns the bytes of the loaded records.
+func (p *LoadProgress) Bytes() int64 { return p.bytes.Load() }
+
+// Lines returns the number of the loaded records.
+func (p *LoadProgress) Lines() int64 { return p.lines.Load() }
+
+// Total returns the size of the source, 0 if unknown.
+func (p *LoadProgress) Total() int64 { return p.total.Load() }
+
+// Done returns true if the source is loaded.
+func (p *LoadProgress) Done() bool { return p.done.Load() }
+
+func (p *LoadProgress) add(size int) {
+	p.bytes.Add(int64(size))
+	p.lines.Add(1)
+}
+
+// Progress is the LoadProgress of the sources.
+// This is safe for concurrent use.
+type Progress struct {
+	mux     sync.Mutex
+	sources map[int]*LoadProgress
+}
+
+func NewProgress() *Progress {
+	return &Progress{
+		sources: make(map[int]*LoadProgress),
+	}
+}
+
+// Source returns the progress of the zero-based source.
+func (p *Progress) Source(src int) *LoadProgress {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if x, ok := p.sources[src]; ok {
+		return x
+	}
+	x := &LoadProgress{}
+	p.sources[src] = x
+	return x
+}
+
+// Sources returns the zero-based sources that started loading.
+func (p *Progress) Sources() []int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	r := make([]int, 0, len(p.sources))
+	for src := range p.sources {
+		r = append(r, src)
+	}
+	sort.Ints(r)
+	return r
+}
@@ -0,0 +1,86 @@
+package joiner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MalformedPolicy decides how to handle the lines whose keys cannot be extracted.
+type MalformedPolicy int
+
+const (
+	// MalformedFail aborts the loading.
+	MalformedFail MalformedPolicy = iota
+	// MalformedSkip excludes the line from all indexes of the source.
+	MalformedSkip
+	// MalformedEmpty indexes the line with the empty key in place of the missing column,
+	// the joins and the selections read the missing key columns as empty too.
+	MalformedEmpty
+)
+
+var malformedPolicyNames = map[MalformedPolicy]string{
+	MalformedFail:  "fail",
+	MalformedSkip:  "skip",
+	MalformedEmpty: "empty",
+}
+
+func (p MalformedPolicy) String() string {
+	if s, ok := malformedPolicyNames[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("MalformedPolicy(%d)", int(p))
+}
+
+var ErrUnknownMalformedPolicy = errors.New("UnknownMalformedPolicy")
+
+// ParseMalformedPolicy parses fail, skip or empty.
+func ParseMalformedPolicy(s string) (MalformedPolicy, error) {
+	for p, name := range malformedPolicyNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	return MalformedFail, fmt.Errorf("%w %q, want fail, skip or empty", ErrUnknownMalformedPolicy, s)
+}
+
+//go:generate go run github.com/berquerant/dataclass@v0.3.1 -type Rejection -field "Source int|Line int|Text string|Reason error" -output reject_dataclass_rejection_generated.go
+
+// Rejecter receives the malformed lines.
+// Source of the rejection is zero-based, line is one-based.
+type Rejecter interface {
+	Reject(r Rejection) error
+	// Count returns the number of the rejected lines.
+	Count() int
+}
+
+// NewRejecter returns a Rejecter that writes the rejections to w,
+// one line per rejection: source (one-based), line number, reason and the line itself separated by tabs.
+func NewRejecter(w io.Writer) Rejecter {
+	return &rejecter{
+		w: w,
+	}
+}
+
+type rejecter struct {
+	sync.Mutex
+	w     io.Writer
+	count int
+}
+
+func (r *rejecter) Reject(x Rejection) error {
+	r.Lock()
+	defer r.Unlock()
+	r.count++
+	if _, err := fmt.Fprintf(r.w, "%d\t%d\t%s\t%s\n", x.Source()+1, x.Line(), x.Reason(), x.Text()); err != nil {
+		return fmt.Errorf("Reject: %w", err)
+	}
+	return nil
+}
+
+func (r *rejecter) Count() int {
+	r.Lock()
+	defer r.Unlock()
+	return r.count
+}
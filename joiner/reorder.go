@@ -0,0 +1,114 @@
+package joiner
+
+import (
+	"github.com/berquerant/joiny/cc/joinkey"
+)
+
+// Statistics provides the statistics of the columns.
+type Statistics interface {
+	// ColumnStats returns the statistics of the zero-based column of the zero-based source.
+	ColumnStats(src, col int) (IndexStats, bool)
+}
+
+func (c *cache) ColumnStats(src, col int) (IndexStats, bool) {
+	idx, found := c.Get(src, col)
+	if !found {
+		return IndexStats{}, false
+	}
+	return idx.Stats(), true
+}
+
+type sampleStatistics []*SourceSample
+
+// NewSampleStatistics returns the statistics estimated from the samples of the sources.
+func NewSampleStatistics(samples []*SourceSample) Statistics { return sampleStatistics(samples) }
+
+func (s sampleStatistics) ColumnStats(src, col int) (IndexStats, bool) {
+	if src < 0 || src >= len(s) {
+		return IndexStats{}, false
+	}
+	return IndexStats{
+		Rows:     s[src].Rows(),
+		Distinct: s[src].DistinctKeys(col),
+	}, true
+}
+
+func relationStats(stats Statistics, rel *joinkey.Relation) (IndexStats, IndexStats) {
+	lKey, rKey := rel.Left.Add(-1, -1), rel.Right.Add(-1, -1)
+	l, _ := stats.ColumnStats(lKey.Src, lKey.Col)
+	r, _ := stats.ColumnStats(rKey.Src, rKey.Col)
+	return l, r
+}
+
+// selectivity returns the ratio of the rows which match by the relation, 1 / max(distinct(L), distinct(R)).
+func selectivity(l, r IndexStats) float64 {
+	return 1 / float64(max(l.Distinct, r.Distinct, 1))
+}
+
+// Reorder reorders the relations to reduce the intermediate rows.
+// It starts from the relation with the fewest estimated rows, then applies filters as soon as possible
+// and the joins in order of the estimated fan-out.
+// The sides of the first relation are swapped so that the smaller side is scanned.
+// The output is identical to the original key up to the order of the rows.
+func Reorder(key *joinkey.JoinKey, stats Statistics) *joinkey.JoinKey {
+	if len(key.RelationList) == 0 {
+		return key
+	}
+
+	var (
+		rest   = append([]*joinkey.Relation{}, key.RelationList...)
+		result = make([]*joinkey.Relation, 0, len(rest))
+		joined = make(map[int]bool)
+		take   = func(i int) *joinkey.Relation {
+			x := rest[i]
+			rest = append(rest[:i], rest[i+1:]...)
+			joined[x.Left.Src] = true
+			joined[x.Right.Src] = true
+			return x
+		}
+	)
+
+	first, firstCost := 0, -1.0
+	for i, rel := range rest {
+		l, r := relationStats(stats, rel)
+		cost := float64(l.Rows) * float64(r.Rows) * selectivity(l, r)
+		if firstCost < 0 || cost < firstCost {
+			first, firstCost = i, cost
+		}
+	}
+	head := take(first)
+	if l, r := relationStats(stats, head); r.Rows < l.Rows {
+		head = joinkey.NewRelation(head.Right, head.Left)
+	}
+	result = append(result, head)
+
+	for len(rest) > 0 {
+		next, nextCost := -1, 0.0
+		for i, rel := range rest {
+			lJoined, rJoined := joined[rel.Left.Src], joined[rel.Right.Src]
+			if !lJoined && !rJoined {
+				continue
+			}
+			var (
+				l, r = relationStats(stats, rel)
+				cost float64 // the ratio of the rows after the relation
+			)
+			switch {
+			case lJoined && rJoined:
+				cost = selectivity(l, r) // filter
+			case lJoined:
+				cost = float64(r.Rows) * selectivity(l, r)
+			default:
+				cost = float64(l.Rows) * selectivity(l, r)
+			}
+			if next < 0 || cost < nextCost {
+				next, nextCost = i, cost
+			}
+		}
+		if next < 0 { // disconnected, keep the order
+			next = 0
+		}
+		result = append(result, take(next))
+	}
+	return joinkey.NewJoinKey(result)
+}
@@ -0,0 +1,70 @@
+package joiner_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStatistics map[[2]int]joiner.IndexStats
+
+func (m mockStatistics) ColumnStats(src, col int) (joiner.IndexStats, bool) {
+	s, ok := m[[2]int{src, col}]
+	return s, ok
+}
+
+func TestReorder(t *testing.T) {
+	stats := mockStatistics{
+		// source 1, large fact
+		{0, 0}: {Rows: 10000, Distinct: 100, MaxFanOut: 200},
+		{0, 1}: {Rows: 10000, Distinct: 10000, MaxFanOut: 1},
+		// source 2, large
+		{1, 0}: {Rows: 5000, Distinct: 100, MaxFanOut: 100},
+		{1, 1}: {Rows: 5000, Distinct: 50, MaxFanOut: 100},
+		// source 3, small lookup
+		{2, 0}: {Rows: 10, Distinct: 10, MaxFanOut: 1},
+		// source 4, lookup
+		{3, 0}: {Rows: 100, Distinct: 100, MaxFanOut: 1},
+	}
+
+	for _, tc := range []struct {
+		title string
+		input string
+		want  string
+	}{
+		{
+			title: "single",
+			input: "1.1=2.1",
+			want:  "2.1=1.1",
+		},
+		{
+			title: "start from the selective relation",
+			input: "1.1=2.1,2.2=3.1",
+			want:  "3.1=2.2,1.1=2.1",
+		},
+		{
+			title: "filter first",
+			input: "1.1=2.1,2.2=3.1,1.2=4.1,1.1=2.2",
+			want:  "4.1=1.2,1.1=2.1,1.1=2.2,2.2=3.1",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got := joiner.Reorder(parseKey(t, tc.input), stats)
+			assert.Equal(t, tc.want, got.Expr())
+		})
+	}
+}
+
+func parseKey(t *testing.T, input string) *joinkey.JoinKey {
+	t.Helper()
+	lex := joinkey.NewLexer(bytes.NewBufferString(input))
+	_ = joinkey.Parse(lex)
+	if err := lex.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lex.JoinKey
+}
@@ -60,6 +60,10 @@ type Selector interface {
 	// Select forms selected items into a line depending on the target.
 	// items specify the data sources, target is columns to be selected.
 	Select(tgt *target.Target, items []SelectItem) (string, error)
+	// Sources reads the lines of the items and splits them into columns.
+	// The lines already read by the joins are not read again.
+	// The result is sorted by source, can be passed to SelectColumnsByTarget.
+	Sources(items []SelectItem) ([][]string, error)
 }
 
 func NewSelector(cache Cache) Selector {
@@ -72,7 +76,7 @@ type selector struct {
 	cache Cache
 }
 
-func (s *selector) Select(tgt *target.Target, items []SelectItem) (string, error) {
+func (s *selector) Sources(items []SelectItem) ([][]string, error) {
 	items = slicing.Uniq(items, func(item SelectItem) int { return item.Source() })
 	sort.Slice(items, func(i, j int) bool { return items[i].Source() < items[j].Source() })
 
@@ -80,20 +84,27 @@ func (s *selector) Select(tgt *target.Target, items []SelectItem) (string, error
 	for i, item := range items {
 		srcs, found := s.cache.GetBySrc(item.Source())
 		if !found {
-			return "", fmt.Errorf("Select: %w %v", ErrInvalidRange, item)
+			return nil, fmt.Errorf("Select: %w %v", ErrInvalidRange, item)
 		}
-		src := srcs[0]
-		scanned, err := src.Read(item.Item())
+		fields, err := readFields(srcs[0], item)
 		if err != nil {
-			return "", fmt.Errorf("Select: %w %v", err, item)
+			return nil, fmt.Errorf("Select: %w %v", err, item)
 		}
-		lines[i] = strings.Split(scanned.Line(), s.cache.Delimiter())
+		lines[i] = fields
+	}
+	return lines, nil
+}
+
+func (s *selector) Select(tgt *target.Target, items []SelectItem) (string, error) {
+	lines, err := s.Sources(items)
+	if err != nil {
+		return "", err
 	}
 	selected, err := SelectColumnsByTarget(tgt, lines)
 	if err != nil {
 		return "", fmt.Errorf("Select: %w", err)
 	}
-	logx.G().Debug("Select",
+	logx.C(logx.Select).Debug("Select",
 		logx.Any("items", items),
 		logx.Any("target", tgt),
 		logx.Any("lines", lines),
@@ -2,8 +2,11 @@ package joiner_test
 
 import (
 	"context"
+	"iter"
+	"strings"
 	"testing"
 
+	"github.com/berquerant/joiny/async"
 	"github.com/berquerant/joiny/cc/target"
 	"github.com/berquerant/joiny/joiner"
 	"github.com/stretchr/testify/assert"
@@ -116,10 +119,14 @@ type mockIndex struct {
 	v map[string]string
 }
 
-func (*mockIndex) KeyFunc() joiner.KeyFunc                          { return nil }
-func (*mockIndex) Scan(_ context.Context) <-chan joiner.ScannedItem { return nil }
-func (*mockIndex) Get(_ string) ([]joiner.Item, bool)               { return nil, false }
-func (*mockIndex) AllItems(_ context.Context) <-chan joiner.Item    { return nil }
+func (*mockIndex) KeyFunc() joiner.KeyFunc                         { return nil }
+func (*mockIndex) Stats() joiner.IndexStats                        { return joiner.IndexStats{} }
+func (*mockIndex) Scan(_ context.Context) <-chan joiner.ScanResult { return nil }
+func (*mockIndex) Get(_ string) ([]joiner.Item, bool)              { return nil, false }
+func (*mockIndex) AllItems(_ context.Context) <-chan joiner.Item   { return nil }
+func (*mockIndex) Lines() iter.Seq2[joiner.ScannedItem, error]     { return nil }
+func (*mockIndex) Items() iter.Seq[joiner.Item]                    { return nil }
+func (*mockIndex) Split(line string) ([]string, error)             { return strings.Split(line, ","), nil }
 func (m *mockIndex) Read(item joiner.Item) (joiner.ScannedItem, error) {
 	// find line by key
 	return joiner.NewScannedItem(m.v[item.Key()], item), nil
@@ -130,7 +137,14 @@ type mockCache struct {
 }
 
 func (*mockCache) Delimiter() string                 { return "," }
+func (*mockCache) Prefilter() joiner.Prefilter       { return nil }
 func (*mockCache) Get(_, _ int) (joiner.Index, bool) { return nil, false }
+func (*mockCache) ReadStats(_ int) (async.CacheStats, bool) {
+	return async.CacheStats{}, false
+}
+func (*mockCache) ColumnStats(_, _ int) (joiner.IndexStats, bool) {
+	return joiner.IndexStats{}, false
+}
 func (m *mockCache) GetBySrc(src int) ([]joiner.Index, bool) {
 	return []joiner.Index{m.v[src]}, true
 }
@@ -0,0 +1,45 @@
+package joiner
+
+// ColumnReport is the key distribution of an index.
+type ColumnReport struct {
+	IndexStats
+	// Empty is the number of the lines of the empty key.
+	Empty int
+	// Top is the most frequent keys.
+	Top []KeyCount
+}
+
+// NewColumnReport reports the key distribution of the index with top n keys.
+func NewColumnReport(idx Index, n int) ColumnReport {
+	counts := make(map[string]int)
+	for item := range idx.Items() {
+		counts[item.Key()]++
+	}
+	top := make([]KeyCount, 0, len(counts))
+	for k, c := range counts {
+		top = append(top, KeyCount{
+			Key:   k,
+			Count: c,
+		})
+	}
+	sortKeyCounts(top)
+	if len(top) > n {
+		top = top[:max(n, 0)]
+	}
+	return ColumnReport{
+		IndexStats: idx.Stats(),
+		Empty:      counts[""],
+		Top:        top,
+	}
+}
+
+// JoinSize returns the number of the rows of the join between the indexes.
+func JoinSize(left, right Index) int {
+	var size int
+	for item := range left.Items() {
+		if items, ok := right.Get(item.Key()); ok {
+			size += len(items)
+		}
+	}
+	return size
+}
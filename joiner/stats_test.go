@@ -0,0 +1,54 @@
+package joiner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/berquerant/joiny/joiner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnReport(t *testing.T) {
+	g := &multiSourceGenerator{}
+	g.add("a,1|a,x")
+	g.add(",2|b,y")
+	g.add("a,3|b,z")
+	g.add("c,4|a,w")
+	g.add(",5|d,v")
+	g.generate()
+	defer g.close()
+
+	key := parseKey(t, "1.1=2.1")
+	cache, err := joiner.NewCacheBuilder(
+		g.readSeekers(),
+		joiner.RelationListToLocationList(key.RelationList),
+		",",
+		-1,
+		10,
+	).Build(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, _ := cache.Get(0, 0)
+	right, _ := cache.Get(1, 0)
+
+	t.Run("report", func(t *testing.T) {
+		assert.Equal(t, joiner.ColumnReport{
+			IndexStats: joiner.IndexStats{
+				Rows:      5,
+				Distinct:  3,
+				MaxFanOut: 2,
+			},
+			Empty: 2,
+			Top: []joiner.KeyCount{
+				{Key: "", Count: 2},
+				{Key: "a", Count: 2},
+			},
+		}, joiner.NewColumnReport(left, 2))
+	})
+
+	t.Run("join size", func(t *testing.T) {
+		assert.Equal(t, 4, joiner.JoinSize(left, right))
+		assert.Equal(t, 4, joiner.JoinSize(right, left))
+	})
+}
@@ -0,0 +1,51 @@
+package joiner
+
+import (
+	"sync"
+	"time"
+)
+
+// Timing is the elapsed time of a phase of building indexes.
+type Timing struct {
+	Phase   string
+	Source  int // zero-based, -1 if the phase is not of a source
+	Elapsed time.Duration
+}
+
+// Timings collects the timings of the phases.
+// This is safe for concurrent use.
+type Timings struct {
+	mux  sync.Mutex
+	list []Timing
+}
+
+func NewTimings() *Timings {
+	return &Timings{}
+}
+
+// List returns the timings in the order of the ends of the phases.
+func (t *Timings) List() []Timing {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	r := make([]Timing, len(t.list))
+	copy(r, t.list)
+	return r
+}
+
+func (t *Timings) add(phase string, src int, elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.list = append(t.list, Timing{
+		Phase:   phase,
+		Source:  src,
+		Elapsed: elapsed,
+	})
+}
+
+// since records the phase that started at startAt.
+func (t *Timings) since(phase string, src int, startAt time.Time) {
+	t.add(phase, src, time.Since(startAt))
+}
@@ -0,0 +1,136 @@
+package joiner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"sort"
+	"sync"
+
+	"github.com/berquerant/joiny/async"
+	"github.com/berquerant/joiny/cc/joinkey"
+)
+
+// Tracker records the items that appear in the joined rows.
+type Tracker interface {
+	// Track marks the items of the row as matched, except the null rows.
+	Track(row SelectItemList)
+	// Unmatched returns the items of the source (zero-based) that are never tracked, sorted by offset.
+	Unmatched(ctx context.Context, src int) ([]Item, error)
+	// WriteUnmatched writes the lines of the unmatched items of the source (zero-based) to w.
+	WriteUnmatched(ctx context.Context, w io.Writer, src int) (int, error)
+}
+
+func NewTracker(cache Cache) Tracker {
+	return &tracker{
+		cache:   cache,
+		touched: make(map[int]map[int64]struct{}),
+	}
+}
+
+type tracker struct {
+	sync.Mutex
+	cache   Cache
+	touched map[int]map[int64]struct{}
+}
+
+func (t *tracker) Track(row SelectItemList) {
+	t.Lock()
+	defer t.Unlock()
+	for src, item := range row {
+		if IsNull(item) {
+			continue
+		}
+		m, ok := t.touched[src]
+		if !ok {
+			m = make(map[int64]struct{})
+			t.touched[src] = m
+		}
+		// offset identifies the line in the source
+		m[item.Item().Offset()] = struct{}{}
+	}
+}
+
+func (t *tracker) index(src int) (Index, error) {
+	idxs, ok := t.cache.GetBySrc(src)
+	if !ok || len(idxs) == 0 {
+		return nil, fmt.Errorf("%w: source %d", ErrIndexNotFound, src+1)
+	}
+	return idxs[0], nil
+}
+
+func (t *tracker) Unmatched(ctx context.Context, src int) ([]Item, error) {
+	idx, err := t.index(src)
+	if err != nil {
+		return nil, fmt.Errorf("Unmatched: %w", err)
+	}
+
+	t.Lock()
+	touched := t.touched[src]
+	t.Unlock()
+
+	var r []Item
+	// every line appears once in an index
+	for item := range idx.Items() {
+		if _, ok := touched[item.Offset()]; !ok {
+			r = append(r, item)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Offset() < r[j].Offset() })
+	return r, nil
+}
+
+func (t *tracker) WriteUnmatched(ctx context.Context, w io.Writer, src int) (int, error) {
+	items, err := t.Unmatched(ctx, src)
+	if err != nil {
+		return 0, err
+	}
+	idx, err := t.index(src)
+	if err != nil {
+		return 0, fmt.Errorf("WriteUnmatched: %w", err)
+	}
+	for i, item := range items {
+		if async.Done(ctx) {
+			return i, fmt.Errorf("WriteUnmatched: %w", ctx.Err())
+		}
+		scanned, err := idx.Read(item)
+		if err != nil {
+			return i, fmt.Errorf("WriteUnmatched: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, scanned.Line()); err != nil {
+			return i, fmt.Errorf("WriteUnmatched: %w", err)
+		}
+	}
+	return len(items), nil
+}
+
+// NewTrackingJoiner returns a Joiner that tracks the joined rows of j.
+func NewTrackingJoiner(j Joiner, t Tracker) Joiner {
+	return &trackingJoiner{
+		joiner:  j,
+		tracker: t,
+	}
+}
+
+type trackingJoiner struct {
+	joiner  Joiner
+	tracker Tracker
+}
+
+func (j *trackingJoiner) Join(ctx context.Context, key *joinkey.JoinKey) <-chan JoinResult {
+	return joinResultChan(ctx, j.JoinSeq(key))
+}
+
+func (j *trackingJoiner) JoinSeq(key *joinkey.JoinKey) iter.Seq2[SelectItemList, error] {
+	return func(yield func(SelectItemList, error) bool) {
+		for row, err := range j.joiner.JoinSeq(key) {
+			if err == nil {
+				j.tracker.Track(row)
+			}
+			if !yield(row, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package joiner_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	g := &multiSourceGenerator{}
+	g.add("a,1|a,x")
+	g.add("b,2|c,y")
+	g.add("c,3|d,z")
+	g.add("d,4|a,w")
+	g.generate()
+	defer g.close()
+
+	key := joinkey.NewJoinKey([]*joinkey.Relation{
+		joinkey.NewRelation(joinkey.NewLocation(1, 1), joinkey.NewLocation(2, 1)),
+	})
+	cache, err := joiner.NewCacheBuilder(
+		g.readSeekers(),
+		joiner.RelationListToLocationList(key.RelationList),
+		",",
+		-1,
+		10,
+	).Build(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := joiner.NewTracker(cache)
+	j := joiner.NewTrackingJoiner(joiner.New(joiner.NewRelationJoiner(cache)), tracker)
+	var count int
+	for x := range j.Join(context.TODO(), key) {
+		if err := x.Err(); err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	assert.Equal(t, 4, count)
+
+	for _, tc := range []struct {
+		title string
+		src   int
+		want  string
+	}{
+		{
+			title: "left",
+			src:   0,
+			want:  "b,2\n",
+		},
+		{
+			title: "right",
+			src:   1,
+			want:  "",
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			var got bytes.Buffer
+			_, err := tracker.WriteUnmatched(context.TODO(), &got, tc.src)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	t.Run("not indexed", func(t *testing.T) {
+		_, err := tracker.Unmatched(context.TODO(), 2)
+		assert.ErrorIs(t, err, joiner.ErrIndexNotFound)
+	})
+}
@@ -0,0 +1,275 @@
+// Package joiny joins delimited sources by keys.
+package joiny
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/berquerant/joiny/joiner"
+	"github.com/berquerant/joiny/logx"
+	"github.com/berquerant/joiny/source"
+	"github.com/berquerant/joiny/temporary"
+)
+
+// ParseKey parses a join key, like "1.2=2.3".
+func ParseKey(key string) (*joinkey.JoinKey, error) {
+	l := joinkey.NewLexer(bytes.NewBufferString(key))
+	joinkey.Parse(l)
+	if err := l.Err(); err != nil {
+		return nil, err
+	}
+	return l.JoinKey, nil
+}
+
+// ParseTarget parses a target, like "1.1,2.1-".
+func ParseTarget(tgt string) (*target.Target, error) {
+	l := target.NewLexer(bytes.NewBufferString(tgt))
+	target.Parse(l)
+	if err := l.Err(); err != nil {
+		return nil, err
+	}
+	return l.Target, nil
+}
+
+// DefaultKey returns the key that joins n sources by first columns.
+func DefaultKey(n int) string {
+	if n == 1 { // identity join
+		return "1.1=1.1"
+	}
+	ss := make([]string, n-1)
+	for i := range ss {
+		ss[i] = fmt.Sprintf("%d.1=%d.1", i+1, i+2)
+	}
+	return strings.Join(ss, ",")
+}
+
+// DefaultTarget returns the target that selects all columns of n sources.
+func DefaultTarget(n int) string {
+	ss := make([]string, n)
+	for i := range ss {
+		ss[i] = fmt.Sprintf("%d.1-", i+1)
+	}
+	return strings.Join(ss, ",")
+}
+
+var ErrNoSources = errors.New("NoSources")
+
+// Join joins the sources.
+// sources[0] is the source 1, sources[1] is the source 2.
+// The sources that are not io.ReadSeeker are copied into temporary files.
+// Indexes are built before returning, so the errors of the key, the target and the sources are returned here.
+// The returned Rows must be closed.
+func Join(ctx context.Context, sources []io.Reader, opt ...Option) (*Rows, error) {
+	if len(sources) == 0 {
+		return nil, ErrNoSources
+	}
+	c := newConfig(opt...)
+
+	var (
+		files    temporary.FileList
+		dataList = make([]source.Source, len(sources))
+	)
+	for i, src := range sources {
+		if rs, ok := src.(io.ReadSeeker); ok {
+			dataList[i] = source.NewDelimited(rs, c.delimiter)
+			continue
+		}
+		f, err := temporary.NewFile()
+		if err != nil {
+			files.Close()
+			return nil, fmt.Errorf("source %d: %w", i+1, err)
+		}
+		files = append(files, f)
+		if _, err := io.Copy(f, src); err != nil {
+			files.Close()
+			return nil, fmt.Errorf("source %d: %w", i+1, err)
+		}
+		dataList[i] = source.NewDelimited(f, c.delimiter)
+	}
+
+	rows, err := join(ctx, c, dataList)
+	if err != nil {
+		files.Close()
+		return nil, err
+	}
+	rows.files = files
+	return rows, nil
+}
+
+// JoinSources joins the sources, e.g. JSON Lines and SQLite tables, see package source.
+// The sources should be closed after the returned Rows.
+func JoinSources(ctx context.Context, sources []source.Source, opt ...Option) (*Rows, error) {
+	if len(sources) == 0 {
+		return nil, ErrNoSources
+	}
+	return join(ctx, newConfig(opt...), sources)
+}
+
+func join(ctx context.Context, c *config, sources []source.Source) (*Rows, error) {
+	jKey, err := c.parseKey(len(sources))
+	if err != nil {
+		return nil, err
+	}
+	tgt, err := c.parseTarget(len(sources))
+	if err != nil {
+		return nil, err
+	}
+	if err := jKey.Validate(len(sources)); err != nil {
+		return nil, fmt.Errorf("invalid key %s: %w", jKey.Expr(), err)
+	}
+	// each relation should share a source with the preceding relations
+	jKey = jKey.Connected()
+
+	cache, err := joiner.NewSourceCacheBuilder(
+		sources,
+		joiner.RelationListToLocationList(jKey.RelationList),
+		c.delimiter,
+		c.loadThread,
+		c.cacheSize,
+		c.loadOptions...,
+	).Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.reorder {
+		jKey = joiner.Reorder(jKey, cache)
+	}
+
+	next, stop := iter.Pull2(joiner.New(joiner.NewRelationJoiner(cache)).JoinSeq(jKey))
+	return &Rows{
+		ctx:      ctx,
+		next:     next,
+		stop:     stop,
+		selector: joiner.NewSelector(cache),
+		target:   tgt,
+		lenient:  c.lenient,
+	}, nil
+}
+
+func (c *config) parseKey(n int) (*joinkey.JoinKey, error) {
+	if c.joinKey != nil {
+		return c.joinKey, nil
+	}
+	if c.key == "" {
+		return ParseKey(DefaultKey(n))
+	}
+	return ParseKey(c.key)
+}
+
+func (c *config) parseTarget(n int) (*target.Target, error) {
+	if c.targetAST != nil {
+		return c.targetAST, nil
+	}
+	if c.target == "" {
+		return ParseTarget(DefaultTarget(n))
+	}
+	return ParseTarget(c.target)
+}
+
+// Rows is the result of Join.
+//
+//	rows, err := joiny.Join(ctx, sources)
+//	...
+//	defer rows.Close()
+//	for rows.Next() {
+//		fmt.Println(rows.Row())
+//	}
+//	if err := rows.Err(); err != nil {
+//		...
+//	}
+type Rows struct {
+	ctx      context.Context
+	next     func() (joiner.SelectItemList, error, bool)
+	stop     func()
+	files    temporary.FileList
+	selector joiner.Selector
+	target   *target.Target
+	lenient  bool
+
+	row    []string
+	err    error
+	closed bool
+}
+
+// Next prepares the next row for Row.
+// Returns false if no rows remain or an error occurred.
+func (r *Rows) Next() bool {
+	if r.closed || r.err != nil {
+		return false
+	}
+	for {
+		if err := r.ctx.Err(); err != nil {
+			r.err = err
+			r.stop()
+			return false
+		}
+		items, err, ok := r.next()
+		if !ok {
+			return false
+		}
+		row, err := r.read(items, err)
+		if err != nil {
+			if r.lenient {
+				logx.G().Warn("Skip row", logx.Err(err))
+				continue
+			}
+			r.err = err
+			r.stop()
+			return false
+		}
+		r.row = row
+		return true
+	}
+}
+
+// All iterates the rest of the rows.
+// The iteration stops at the first error.
+func (r *Rows) All() iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		for r.Next() {
+			if !yield(r.Row(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+func (r *Rows) read(items joiner.SelectItemList, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	sources, err := r.selector.Sources(items.Sorted())
+	if err != nil {
+		return nil, err
+	}
+	return joiner.SelectColumnsByTarget(r.target, sources)
+}
+
+// Row returns the columns of the current row.
+func (r *Rows) Row() []string { return r.row }
+
+// Err returns the error occurred during the iteration.
+func (r *Rows) Err() error { return r.err }
+
+// Close stops the join and releases the resources.
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.stop()
+	if len(r.files) == 0 {
+		return nil
+	}
+	return r.files.Close()
+}
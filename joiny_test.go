@@ -0,0 +1,186 @@
+package joiny_test
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny"
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/source"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	accounts = `1,account1,HR
+2,account2,Dev
+4,account4,HR
+3,account3,PR
+`
+	departments = `10,HR,Human Resources
+12,PR,Public Relations
+11,Dev,Development
+`
+)
+
+// onlyReader hides io.Seeker.
+type onlyReader struct {
+	io.Reader
+}
+
+func TestJoin(t *testing.T) {
+	for _, tc := range []struct {
+		title   string
+		sources []io.Reader
+		opt     []joiny.Option
+		want    []string
+		err     bool
+	}{
+		{
+			title:   "default",
+			sources: []io.Reader{strings.NewReader(accounts)},
+			want: []string{
+				"1,account1,HR",
+				"2,account2,Dev",
+				"3,account3,PR",
+				"4,account4,HR",
+			},
+		},
+		{
+			title: "key and target",
+			sources: []io.Reader{
+				strings.NewReader(accounts),
+				&onlyReader{strings.NewReader(departments)},
+			},
+			opt: []joiny.Option{
+				joiny.WithKey("1.3=2.2"),
+				joiny.WithTarget("-1.2,2.3"),
+			},
+			want: []string{
+				"1,account1,Human Resources",
+				"2,account2,Development",
+				"3,account3,Public Relations",
+				"4,account4,Human Resources",
+			},
+		},
+		{
+			title: "ast",
+			sources: []io.Reader{
+				&onlyReader{strings.NewReader(accounts)},
+				&onlyReader{strings.NewReader(departments)},
+			},
+			opt: []joiny.Option{
+				joiny.WithJoinKey(joinkey.NewJoinKey([]*joinkey.Relation{
+					joinkey.NewRelation(joinkey.NewLocation(2, 2), joinkey.NewLocation(1, 3)),
+				})),
+				joiny.WithTarget("2.1,1.1"),
+			},
+			want: []string{
+				"10,1",
+				"10,4",
+				"11,2",
+				"12,3",
+			},
+		},
+		{
+			title: "unordered key without reorder",
+			sources: []io.Reader{
+				strings.NewReader("1,p\n2,q\n"),
+				strings.NewReader("1,A\n2,B\n"),
+				strings.NewReader("X,A\nY,B\n"),
+				strings.NewReader("X,x4\nY,y4\n"),
+			},
+			opt: []joiny.Option{
+				joiny.WithKey("1.1=2.1,3.1=4.1,2.2=3.2"),
+				joiny.WithTarget("1.2,4.2"),
+				joiny.WithReorder(false),
+			},
+			want: []string{
+				"p,x4",
+				"q,y4",
+			},
+		},
+		{
+			title: "invalid key",
+			sources: []io.Reader{
+				strings.NewReader(accounts),
+				strings.NewReader(departments),
+			},
+			opt: []joiny.Option{
+				joiny.WithKey("1.3=3.2"),
+			},
+			err: true,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			rows, err := joiny.Join(context.TODO(), tc.sources, tc.opt...)
+			if tc.err {
+				assert.NotNil(t, err)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			defer rows.Close()
+
+			got := []string{}
+			for rows.Next() {
+				got = append(got, strings.Join(rows.Row(), ","))
+			}
+			assert.Nil(t, rows.Err())
+			sort.Strings(got)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("all", func(t *testing.T) {
+		rows, err := joiny.Join(context.TODO(), []io.Reader{strings.NewReader(accounts)})
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer rows.Close()
+		var count int
+		for row, err := range rows.All() {
+			assert.Nil(t, err)
+			assert.Equal(t, 3, len(row))
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("close early", func(t *testing.T) {
+		rows, err := joiny.Join(context.TODO(), []io.Reader{strings.NewReader(accounts)})
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.True(t, rows.Next())
+		assert.Nil(t, rows.Close())
+		assert.False(t, rows.Next())
+	})
+}
+
+func TestJoinSources(t *testing.T) {
+	rows, err := joiny.JoinSources(context.TODO(), []source.Source{
+		source.NewDelimited(strings.NewReader(accounts), ","),
+		source.NewRows([][]string{
+			{"HR", "Human Resources"},
+			{"Dev", "Development"},
+		}),
+	}, joiny.WithKey("1.3=2.1"), joiny.WithTarget("1.1,2.2"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer rows.Close()
+	got := []string{}
+	for row, err := range rows.All() {
+		assert.Nil(t, err)
+		got = append(got, strings.Join(row, ","))
+	}
+	sort.Strings(got)
+	assert.Equal(t, []string{"1,Human Resources", "2,Development", "4,Human Resources"}, got)
+}
@@ -1,9 +1,15 @@
 package logx
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/exp/constraints"
@@ -30,18 +36,125 @@ func (l Level) intoSlog() slog.Level {
 	}
 }
 
+func (l Level) String() string {
+	switch l {
+	case Linfo:
+		return "info"
+	case Ldebug:
+		return "debug"
+	case Ltrace:
+		return "trace"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrUnknownLevel = errors.New("UnknownLevel")
+
+func ParseLevel(s string) (Level, error) {
+	for _, l := range []Level{Linfo, Ldebug, Ltrace} {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownLevel, s)
+}
+
+// Format is the format of the logs.
+type Format int
+
+const (
+	FormatJSON Format = iota
+	FormatText
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatText:
+		return "text"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrUnknownFormat = errors.New("UnknownFormat")
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range []Format{FormatJSON, FormatText} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownFormat, s)
+}
+
+// Component is a stage of the process that has its own level.
+type Component string
+
+const (
+	Parser Component = "parser"
+	Index  Component = "index"
+	Join   Component = "join"
+	Select Component = "select"
+)
+
+var (
+	Components          = []Component{Parser, Index, Join, Select}
+	ErrUnknownComponent = errors.New("UnknownComponent")
+)
+
+// ParseComponentLevels parses the levels of the components, like "parser=info,join=trace".
+func ParseComponentLevels(s string) (map[Component]Level, error) {
+	r := make(map[Component]Level)
+	if s == "" {
+		return r, nil
+	}
+	for _, x := range strings.Split(s, ",") {
+		c, l, ok := strings.Cut(strings.TrimSpace(x), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownComponent, x)
+		}
+		component := Component(c)
+		if !isComponent(component) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownComponent, c)
+		}
+		level, err := ParseLevel(l)
+		if err != nil {
+			return nil, err
+		}
+		r[component] = level
+	}
+	return r, nil
+}
+
+func isComponent(c Component) bool {
+	for _, x := range Components {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
 type Logger interface {
 	Trace(msg string, v ...Attr)
 	Debug(msg string, v ...Attr)
 	Info(msg string, v ...Attr)
 	Warn(msg string, v ...Attr)
 	Error(msg string, v ...Attr)
+	// SetLevel sets the level of the logger.
+	// The level of the global logger is the default level of the components.
 	SetLevel(level Level)
+	// Enabled returns true if the level is logged.
+	Enabled(level Level) bool
 }
 
 var (
-	setupOnce sync.Once
-	instance  Logger
+	setupOnce  sync.Once
+	instance   *logger
+	components map[Component]*logger
 )
 
 func setup() { setupOnce.Do(setupInstance) }
@@ -60,20 +173,85 @@ func replaceAttr(_ []string, a slog.Attr) slog.Attr {
 	return a
 }
 
+func newHandler(w io.Writer, format Format) slog.Handler {
+	opt := &slog.HandlerOptions{
+		Level:       levelTrace, // the loggers filter the levels
+		ReplaceAttr: replaceAttr,
+	}
+	if format == FormatText {
+		return slog.NewTextHandler(w, opt)
+	}
+	return slog.NewJSONHandler(w, opt)
+}
+
+// root is the destination shared by the loggers.
+type root struct {
+	logger atomic.Pointer[slog.Logger]
+}
+
 func setupInstance() {
-	levelVar := new(slog.LevelVar)
+	r := &root{}
+	r.logger.Store(slog.New(newHandler(os.Stderr, FormatJSON)))
 	instance = &logger{
-		Logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
-			Level:       levelVar,
-			ReplaceAttr: replaceAttr,
-		})),
-		level: levelVar,
+		root:  r,
+		level: new(slog.LevelVar),
+	}
+	components = make(map[Component]*logger, len(Components))
+	for _, c := range Components {
+		components[c] = &logger{
+			root:      r,
+			level:     new(slog.LevelVar),
+			component: c,
+			parent:    instance,
+		}
+	}
+}
+
+// Setup makes the loggers write to w in the format.
+// Default is JSON on stderr.
+func Setup(w io.Writer, format Format) {
+	setup()
+	instance.root.logger.Store(slog.New(newHandler(w, format)))
+}
+
+// Save returns a function that restores the destination, the format and the levels of the loggers as they are now.
+func Save() (restore func()) {
+	setup()
+	var (
+		dest   = instance.root.logger.Load()
+		levels = make(map[*logger]savedLevel, len(components)+1)
+	)
+	levels[instance] = instance.saveLevel()
+	for _, c := range components {
+		levels[c] = c.saveLevel()
+	}
+	return func() {
+		instance.root.logger.Store(dest)
+		for l, s := range levels {
+			l.level.Set(s.level)
+			l.levelSet.Store(s.set)
+		}
+	}
+}
+
+type savedLevel struct {
+	level slog.Level
+	set   bool
+}
+
+func (l *logger) saveLevel() savedLevel {
+	return savedLevel{
+		level: l.level.Level(),
+		set:   l.levelSet.Load(),
 	}
 }
 
 type logger struct {
-	*slog.Logger
-	level *slog.LevelVar
+	root      *root
+	level     *slog.LevelVar
+	component Component
+	parent    *logger // the global logger of a component, nil if global
+	levelSet  atomic.Bool
 }
 
 func (l *logger) Trace(msg string, v ...Attr) { l.logAttrs(levelTrace, msg, v...) }
@@ -82,15 +260,36 @@ func (l *logger) Info(msg string, v ...Attr)  { l.logAttrs(slog.LevelInfo, msg,
 func (l *logger) Warn(msg string, v ...Attr)  { l.logAttrs(slog.LevelWarn, msg, v...) }
 func (l *logger) Error(msg string, v ...Attr) { l.logAttrs(slog.LevelError, msg, v...) }
 
+// minLevel returns the level of the logger, a component without its own level follows the global logger.
+func (l *logger) minLevel() slog.Level {
+	if l.parent != nil && !l.levelSet.Load() {
+		return l.parent.minLevel()
+	}
+	return l.level.Level()
+}
+
+func (l *logger) enabled(level slog.Level) bool { return level >= l.minLevel() }
+
+func (l *logger) Enabled(level Level) bool { return l.enabled(level.intoSlog()) }
+
 func (l *logger) logAttrs(level slog.Level, msg string, v ...Attr) {
-	attrs := make([]slog.Attr, len(v))
+	if !l.enabled(level) {
+		return
+	}
+	attrs := make([]slog.Attr, len(v), len(v)+1)
 	for i, attr := range v {
 		attrs[i] = slog.Attr(attr)
 	}
-	l.LogAttrs(nil, level, msg, attrs...)
+	if l.component != "" {
+		attrs = append(attrs, slog.String("component", string(l.component)))
+	}
+	l.root.logger.Load().LogAttrs(context.Background(), level, msg, attrs...)
 }
 
-func (l *logger) SetLevel(level Level) { l.level.Set(level.intoSlog()) }
+func (l *logger) SetLevel(level Level) {
+	l.level.Set(level.intoSlog())
+	l.levelSet.Store(true)
+}
 
 func getInstance() Logger {
 	setup()
@@ -100,6 +299,16 @@ func getInstance() Logger {
 // G returns the global logger.
 func G() Logger { return getInstance() }
 
+// C returns the logger of the component.
+// The logger follows the level of the global logger until its own level is set.
+func C(c Component) Logger {
+	setup()
+	if x, ok := components[c]; ok {
+		return x
+	}
+	return instance
+}
+
 type Attr slog.Attr
 
 func S(k, v string) Attr                                   { return Attr(slog.String(k, v)) }
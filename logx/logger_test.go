@@ -0,0 +1,82 @@
+package logx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/logx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentLevels(t *testing.T) {
+	t.Cleanup(logx.Save())
+	var buf bytes.Buffer
+	logx.Setup(&buf, logx.FormatText)
+	logx.G().SetLevel(logx.Ldebug)
+	logx.C(logx.Parser).SetLevel(logx.Linfo)
+	logx.C(logx.Join).SetLevel(logx.Ltrace)
+
+	logx.C(logx.Parser).Debug("parser debug")
+	logx.C(logx.Parser).Info("parser info")
+	logx.C(logx.Index).Debug("index debug")
+	logx.C(logx.Index).Trace("index trace")
+	logx.C(logx.Join).Trace("join trace")
+
+	got := buf.String()
+	assert.NotContains(t, got, "parser debug")
+	assert.Contains(t, got, "parser info")
+	assert.Contains(t, got, `msg="index debug" component=index`, "follows the global level")
+	assert.NotContains(t, got, "index trace")
+	assert.Contains(t, got, "level=TRACE")
+	assert.Equal(t, 3, strings.Count(got, "\n"))
+	assert.True(t, logx.C(logx.Join).Enabled(logx.Ltrace))
+	assert.False(t, logx.C(logx.Parser).Enabled(logx.Ldebug))
+}
+
+func TestSave(t *testing.T) {
+	var before, after bytes.Buffer
+	logx.Setup(&before, logx.FormatJSON)
+	t.Cleanup(logx.Save())
+
+	restore := logx.Save()
+	logx.Setup(&after, logx.FormatText)
+	logx.G().SetLevel(logx.Ltrace)
+	logx.C(logx.Index).SetLevel(logx.Linfo)
+	restore()
+
+	assert.False(t, logx.G().Enabled(logx.Ldebug))
+	logx.G().SetLevel(logx.Ldebug)
+	assert.True(t, logx.C(logx.Index).Enabled(logx.Ldebug), "the component follows the global level again")
+	logx.C(logx.Index).Debug("restored")
+	assert.Contains(t, before.String(), `"msg":"restored"`)
+	assert.Empty(t, after.String())
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	got, err := logx.ParseComponentLevels("parser=info, join=trace")
+	assert.Nil(t, err)
+	assert.Equal(t, map[logx.Component]logx.Level{
+		logx.Parser: logx.Linfo,
+		logx.Join:   logx.Ltrace,
+	}, got)
+
+	got, err = logx.ParseComponentLevels("")
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+
+	for _, tc := range []struct {
+		spec string
+		want error
+	}{
+		{spec: "lexer=info", want: logx.ErrUnknownComponent},
+		{spec: "parser", want: logx.ErrUnknownComponent},
+		{spec: "parser=warn", want: logx.ErrUnknownLevel},
+	} {
+		_, err := logx.ParseComponentLevels(tc.spec)
+		assert.ErrorIs(t, err, tc.want, tc.spec)
+	}
+
+	_, err = logx.ParseFormat("xml")
+	assert.ErrorIs(t, err, logx.ErrUnknownFormat)
+}
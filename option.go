@@ -0,0 +1,108 @@
+package joiny
+
+import (
+	"github.com/berquerant/joiny/cc/joinkey"
+	"github.com/berquerant/joiny/cc/target"
+	"github.com/berquerant/joiny/joiner"
+)
+
+type config struct {
+	key         string
+	joinKey     *joinkey.JoinKey
+	target      string
+	targetAST   *target.Target
+	delimiter   string
+	loadThread  int
+	cacheSize   int
+	reorder     bool
+	lenient     bool
+	loadOptions []joiner.LoadOption
+}
+
+func newConfig(opt ...Option) *config {
+	c := &config{
+		delimiter:  ",",
+		loadThread: 4,
+		cacheSize:  16 << 20,
+		reorder:    true,
+	}
+	for _, f := range opt {
+		f(c)
+	}
+	return c
+}
+
+// Option configures Join.
+type Option func(*config)
+
+// WithKey sets the join key, like "1.2=2.3".
+// Default key joins by first columns, e.g. "1.1=2.1".
+func WithKey(key string) Option {
+	return func(c *config) {
+		c.key = key
+	}
+}
+
+// WithJoinKey sets the parsed join key, takes precedence over WithKey.
+func WithJoinKey(key *joinkey.JoinKey) Option {
+	return func(c *config) {
+		c.joinKey = key
+	}
+}
+
+// WithTarget sets the columns of the rows, like "1.1,2.1-".
+// Default target is the all columns.
+func WithTarget(tgt string) Option {
+	return func(c *config) {
+		c.target = tgt
+	}
+}
+
+// WithTargetAST sets the parsed target, takes precedence over WithTarget.
+func WithTargetAST(tgt *target.Target) Option {
+	return func(c *config) {
+		c.targetAST = tgt
+	}
+}
+
+// WithDelimiter sets the delimiter of the columns of the sources. Default is ",".
+func WithDelimiter(delimiter string) Option {
+	return func(c *config) {
+		c.delimiter = delimiter
+	}
+}
+
+// WithLoadThread sets the number of the threads to load the sources. Default is 4.
+func WithLoadThread(n int) Option {
+	return func(c *config) {
+		c.loadThread = n
+	}
+}
+
+// WithCacheSize sets the max bytes of the read cache of each source. Default is 16 MiB.
+func WithCacheSize(n int) Option {
+	return func(c *config) {
+		c.cacheSize = n
+	}
+}
+
+// WithReorder enables reordering the relations by the statistics of the indexes. Default is true.
+func WithReorder(v bool) Option {
+	return func(c *config) {
+		c.reorder = v
+	}
+}
+
+// WithLenient skips the rows that failed to join or select instead of stopping with an error.
+func WithLenient(v bool) Option {
+	return func(c *config) {
+		c.lenient = v
+	}
+}
+
+// WithLoadOptions passes the options to the index loaders of the sources.
+func WithLoadOptions(opt ...joiner.LoadOption) Option {
+	return func(c *config) {
+		c.loadOptions = append(c.loadOptions, opt...)
+	}
+}
@@ -0,0 +1,69 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidJSON = errors.New("InvalidJSON")
+
+// SplitJSON splits a JSON array or object into fields.
+// Strings are unquoted, null is empty, the others are compact JSON texts.
+// The fields of an object are the values in the order of appearance.
+func SplitJSON(text string) ([]string, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	t, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	delim, ok := t.(json.Delim)
+	if !ok || (delim != '[' && delim != '{') {
+		return nil, fmt.Errorf("%w: want array or object, got %s", ErrInvalidJSON, text)
+	}
+	isObject := delim == '{'
+
+	var r []string
+	for dec.More() {
+		if isObject {
+			if _, err := dec.Token(); err != nil { // key
+				return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+			}
+		}
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+		f, err := jsonField(v)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, f)
+	}
+	if _, err := dec.Token(); err != nil { // closing delimiter
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	return r, nil
+}
+
+func jsonField(v json.RawMessage) (string, error) {
+	switch {
+	case bytes.Equal(v, []byte("null")):
+		return "", nil
+	case len(v) > 0 && v[0] == '"':
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+		return s, nil
+	default:
+		var b bytes.Buffer
+		if err := json.Compact(&b, v); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+		return b.String(), nil
+	}
+}
@@ -0,0 +1,149 @@
+package source
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/berquerant/joiny/async"
+)
+
+// NewDelimited returns a source of the lines of data, the fields are separated by the delimiter.
+func NewDelimited(data io.ReadSeeker, delimiter string) File {
+	return newLineSource(data, splitDelimited(delimiter))
+}
+
+func splitDelimited(delimiter string) func(string) ([]string, error) {
+	return func(text string) ([]string, error) {
+		return strings.Split(text, delimiter), nil
+	}
+}
+
+// NewJSONL returns a source of JSON Lines.
+// A line is a JSON array or object, the fields are the elements or the values in the order of appearance.
+func NewJSONL(data io.ReadSeeker) File {
+	return newLineSource(data, SplitJSON)
+}
+
+func newLineSource(data io.ReadSeeker, split func(string) ([]string, error)) *lineSource {
+	locked := async.NewReadSeeker(data)
+	return &lineSource{
+		file:   data,
+		data:   locked,
+		reader: async.NewRangeReader(locked),
+		size: func() (int64, error) {
+			var n int64
+			err := locked.Do(func(data io.ReadSeeker) error {
+				var err error
+				n, err = data.Seek(0, io.SeekEnd)
+				return err
+			})
+			return n, err
+		},
+		split: split,
+	}
+}
+
+// newMappedLineSource returns a source of the lines of the memory-mapped file f.
+func newMappedLineSource(f *os.File, m *async.MmapReader, split func(string) ([]string, error)) *lineSource {
+	return &lineSource{
+		file:   f,
+		data:   m,
+		reader: m,
+		mapped: true,
+		size: func() (int64, error) {
+			return int64(m.Len()), nil
+		},
+		split: split,
+	}
+}
+
+// lineSource is a source of the lines of a file.
+// The handle of a line is its byte range including the line terminator.
+type lineSource struct {
+	file   io.ReadSeeker
+	data   io.ReaderAt
+	reader async.CachedReader
+	mapped bool
+	size   func() (int64, error)
+	split  func(string) ([]string, error)
+}
+
+func (s *lineSource) File() io.ReadSeeker                         { return s.file }
+func (s *lineSource) Split(text string) ([]string, error)         { return s.split(text) }
+func (s *lineSource) Read(offset int64, size int) ([]byte, error) { return s.reader.Read(offset, size) }
+func (s *lineSource) InMemory() bool                              { return s.mapped }
+func (s *lineSource) Size() (int64, error)                        { return s.size() }
+
+// Records reads the lines without holding the lock while yielding,
+// so Read can be called during the iteration.
+func (s *lineSource) Records() iter.Seq2[Record, error] {
+	return s.RecordsRange(0, math.MaxInt64)
+}
+
+// RecordsRange reads the lines that start in [start, end).
+// A line that starts before end is read to its end even if it crosses end.
+func (s *lineSource) RecordsRange(start, end int64) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		var (
+			offset = start
+			isEOF  bool
+			r      = bufio.NewReader(&sequentialReader{
+				data:   s.data,
+				offset: start,
+			})
+		)
+		if start > 0 {
+			// skip the line that starts in the previous range
+			prev := make([]byte, 1)
+			if _, err := s.data.ReadAt(prev, start-1); err != nil {
+				yield(nil, fmt.Errorf("Records: read: offset %d %w", start-1, err))
+				return
+			}
+			if prev[0] != '\n' {
+				skipped, err := r.ReadBytes('\n')
+				if err != nil && !errors.Is(err, io.EOF) {
+					yield(nil, fmt.Errorf("Records: read: offset %d %w", offset, err))
+					return
+				}
+				offset += int64(len(skipped))
+			}
+		}
+		for !isEOF && offset < end {
+			line, err := r.ReadBytes('\n')
+			isEOF = errors.Is(err, io.EOF)
+			if err != nil && !isEOF {
+				yield(nil, fmt.Errorf("Records: read: offset %d %w", offset, err))
+				return
+			}
+			if isEOF && len(line) == 0 {
+				return
+			}
+			size := len(line)
+			if !yield(NewRecord(strings.TrimRight(string(line), "\n"), offset, size), nil) {
+				return
+			}
+			offset += int64(size)
+		}
+	}
+}
+
+// sequentialReader reads data from the head by ReadAt, keeping its own position.
+type sequentialReader struct {
+	data   io.ReaderAt
+	offset int64
+}
+
+func (r *sequentialReader) Read(p []byte) (int, error) {
+	n, err := r.data.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		return n, nil // the next read returns io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,52 @@
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+var ErrNoRecord = errors.New("NoRecord")
+
+// NewRows returns a source of the in-memory rows.
+// The handle of a row is its index, the size is always 0.
+// The text of a row is a JSON array of the fields.
+func NewRows(rows [][]string) Source {
+	return &rowsSource{
+		rows: rows,
+	}
+}
+
+type rowsSource struct {
+	rows [][]string
+}
+
+func (*rowsSource) Split(text string) ([]string, error) { return SplitJSON(text) }
+func (*rowsSource) InMemory() bool                      { return true }
+
+func (s *rowsSource) Records() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for i := range s.rows {
+			b, err := s.Read(int64(i), 0)
+			if err != nil {
+				yield(nil, fmt.Errorf("Records: %w", err))
+				return
+			}
+			if !yield(NewRecord(string(b), int64(i), 0), nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *rowsSource) Read(offset int64, _ int) ([]byte, error) {
+	if offset < 0 || offset >= int64(len(s.rows)) {
+		return nil, fmt.Errorf("Read: %w index %d, %d rows", ErrNoRecord, offset, len(s.rows))
+	}
+	b, err := json.Marshal(s.rows[offset])
+	if err != nil {
+		return nil, fmt.Errorf("Read: index %d %w", offset, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,136 @@
+// Package source provides the records to be joined.
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strings"
+
+	"github.com/berquerant/joiny/async"
+	"github.com/berquerant/joiny/logx"
+)
+
+//go:generate go run github.com/berquerant/dataclass@v0.3.1 -type Record -field "Text string|Offset int64|Size int" -output source_dataclass_record_generated.go
+
+// Source is a sequence of records with stable handles for random access.
+// The handle of a record is the pair of offset and size, the meaning depends on the implementation,
+// e.g. the byte range of a line of a file, the rowid of a table.
+type Source interface {
+	// Records iterates all records in order.
+	// Text of the record does not contain the line terminator.
+	Records() iter.Seq2[Record, error]
+	// Read returns the text of the record of the handle.
+	// The text may end with the line terminator.
+	Read(offset int64, size int) ([]byte, error)
+	// Split splits the text of a record into fields.
+	Split(text string) ([]string, error)
+}
+
+// InMemory reports whether Read of the source is served from memory,
+// such a source needs no cache of the records.
+func InMemory(src Source) bool {
+	x, ok := src.(interface{ InMemory() bool })
+	return ok && x.InMemory()
+}
+
+// Ranged is a Source whose records can be read by byte ranges, e.g. to index a large file in parallel.
+type Ranged interface {
+	Source
+	// Size returns the total bytes of the records.
+	Size() (int64, error)
+	// RecordsRange iterates the records that start in [start, end) in order.
+	RecordsRange(start, end int64) iter.Seq2[Record, error]
+}
+
+// File is a Source backed by a file.
+type File interface {
+	Source
+	// File returns the underlying file.
+	File() io.ReadSeeker
+}
+
+const (
+	jsonlScheme  = "jsonl:"
+	sqliteScheme = "sqlite:"
+)
+
+var ErrInvalidSpec = errors.New("InvalidSpec")
+
+type openConfig struct {
+	mmap bool
+}
+
+// OpenOption configures Open.
+type OpenOption func(*openConfig)
+
+// WithMmap maps the delimited and JSON Lines files into memory if possible.
+// Falls back to the normal reads if the file cannot be mapped, e.g. pipes.
+func WithMmap(enabled bool) OpenOption {
+	return func(c *openConfig) {
+		c.mmap = enabled
+	}
+}
+
+// Open opens the source of the spec.
+//
+//	path                  delimited file
+//	jsonl:path            JSON Lines file
+//	sqlite:path:table     table of SQLite database
+//
+// The returned closer releases the resources of the source.
+func Open(spec, delimiter string, opt ...OpenOption) (Source, io.Closer, error) {
+	c := &openConfig{}
+	for _, f := range opt {
+		f(c)
+	}
+
+	switch {
+	case strings.HasPrefix(spec, jsonlScheme):
+		return c.openLines(strings.TrimPrefix(spec, jsonlScheme), SplitJSON)
+	case strings.HasPrefix(spec, sqliteScheme):
+		x := strings.TrimPrefix(spec, sqliteScheme)
+		i := strings.LastIndex(x, ":")
+		if i < 1 || i == len(x)-1 {
+			return nil, nil, fmt.Errorf("%w: %s, want sqlite:path:table", ErrInvalidSpec, spec)
+		}
+		s, err := OpenSQLite(x[:i], x[i+1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		return s, s, nil
+	default:
+		return c.openLines(spec, splitDelimited(delimiter))
+	}
+}
+
+func (c *openConfig) openLines(path string, split func(string) ([]string, error)) (Source, io.Closer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !c.mmap {
+		return newLineSource(f, split), f, nil
+	}
+	m, err := async.NewMmapReader(f)
+	if err != nil {
+		logx.G().Debug("Open: fallback to read", logx.S("path", path), logx.Err(err))
+		return newLineSource(f, split), f, nil
+	}
+	return newMappedLineSource(f, m, split), closers{m, f}, nil
+}
+
+// closers closes all in order.
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var errs []error
+	for _, x := range c {
+		if err := x.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,200 @@
+package source_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/joiny/source"
+	"github.com/berquerant/joiny/temporary"
+	"github.com/stretchr/testify/assert"
+)
+
+type record struct {
+	text   string
+	fields []string
+}
+
+func assertSource(t *testing.T, src source.Source, want []record) {
+	t.Helper()
+	var got []record
+	for rec, err := range src.Records() {
+		if !assert.Nil(t, err) {
+			return
+		}
+		fields, err := src.Split(rec.Text())
+		assert.Nil(t, err)
+		got = append(got, record{
+			text:   rec.Text(),
+			fields: fields,
+		})
+
+		b, err := src.Read(rec.Offset(), rec.Size())
+		assert.Nil(t, err)
+		read, err := src.Split(strings.TrimRight(string(b), "\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, fields, read, "read %v", rec)
+	}
+	assert.Equal(t, want, got)
+}
+
+func newFile(t *testing.T, content string) *temporary.File {
+	t.Helper()
+	f, err := temporary.NewFile()
+	if err != nil {
+		t.Fatalf("create tmp file %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatalf("write to tmp file %v", err)
+	}
+	return f
+}
+
+func TestDelimited(t *testing.T) {
+	src := source.NewDelimited(newFile(t, "1,a\n\n2,b"), ",")
+	assertSource(t, src, []record{
+		{text: "1,a", fields: []string{"1", "a"}},
+		{text: "", fields: []string{""}},
+		{text: "2,b", fields: []string{"2", "b"}},
+	})
+}
+
+func TestJSONL(t *testing.T) {
+	src := source.NewJSONL(newFile(t, `{"id":1,"name":"a","tags":["x"]}
+[2,null,"b"]
+`))
+	assertSource(t, src, []record{
+		{text: `{"id":1,"name":"a","tags":["x"]}`, fields: []string{"1", "a", `["x"]`}},
+		{text: `[2,null,"b"]`, fields: []string{"2", "", "b"}},
+	})
+}
+
+func TestSplitJSON(t *testing.T) {
+	_, err := source.SplitJSON(`"scalar"`)
+	assert.ErrorIs(t, err, source.ErrInvalidJSON)
+	_, err = source.SplitJSON(`{"broken"`)
+	assert.ErrorIs(t, err, source.ErrInvalidJSON)
+}
+
+func TestSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{
+		`CREATE TABLE "dept table" (id INTEGER, name TEXT, ratio REAL)`,
+		`INSERT INTO "dept table" VALUES (10, 'HR', 0.5), (11, NULL, 2)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	t.Run("records", func(t *testing.T) {
+		src, err := source.OpenSQLite(path, "dept table")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer src.Close()
+		assertSource(t, src, []record{
+			{text: `["10","HR","0.5"]`, fields: []string{"10", "HR", "0.5"}},
+			{text: `["11","","2"]`, fields: []string{"11", "", "2"}},
+		})
+	})
+
+	t.Run("open", func(t *testing.T) {
+		src, closer, err := source.Open("sqlite:"+path+":dept table", ",")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closer.Close()
+		_, ok := src.(source.File)
+		assert.False(t, ok)
+	})
+
+	t.Run("no table", func(t *testing.T) {
+		_, err := source.OpenSQLite(path, "account")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		_, _, err := source.Open("sqlite:"+path, ",")
+		assert.ErrorIs(t, err, source.ErrInvalidSpec)
+	})
+}
+
+func TestRows(t *testing.T) {
+	src := source.NewRows([][]string{{"1", "a"}, {"2", `"b"`}})
+	assertSource(t, src, []record{
+		{text: `["1","a"]`, fields: []string{"1", "a"}},
+		{text: `["2","\"b\""]`, fields: []string{"2", `"b"`}},
+	})
+	_, err := src.Read(2, 0)
+	assert.ErrorIs(t, err, source.ErrNoRecord)
+}
+
+func TestOpenMmap(t *testing.T) {
+	dir := t.TempDir()
+	write := func(t *testing.T, name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("mapped", func(t *testing.T) {
+		src, closer, err := source.Open(write(t, "data.csv", "1,a\n2,b\n"), ",", source.WithMmap(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closer.Close()
+		assert.True(t, source.InMemory(src))
+		assertSource(t, src, []record{
+			{text: "1,a", fields: []string{"1", "a"}},
+			{text: "2,b", fields: []string{"2", "b"}},
+		})
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		src, closer, err := source.Open("jsonl:"+write(t, "empty.jsonl", ""), ",", source.WithMmap(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closer.Close()
+		assert.False(t, source.InMemory(src))
+		assertSource(t, src, nil)
+	})
+}
+
+func TestRecordsRange(t *testing.T) {
+	const content = "k1,a\nk22,bb\n\nk333,ccc"
+	src := source.NewDelimited(newFile(t, content), ",").(source.Ranged)
+	size, err := src.Size()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)), size)
+
+	var want []string
+	for rec, err := range src.Records() {
+		assert.Nil(t, err)
+		want = append(want, rec.Text())
+	}
+
+	// every cut must yield each line exactly once
+	for cut := int64(0); cut <= size; cut++ {
+		var got []string
+		for _, r := range [][2]int64{{0, cut}, {cut, size}} {
+			for rec, err := range src.RecordsRange(r[0], r[1]) {
+				assert.Nil(t, err)
+				got = append(got, rec.Text())
+			}
+		}
+		assert.Equal(t, want, got, "cut %d", cut)
+	}
+}
@@ -0,0 +1,153 @@
+package source
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure-Go driver
+)
+
+// SQLite is a source of the rows of a table of SQLite database.
+// The handle of a row is its rowid, the size is always 0,
+// so the table should have rowid.
+// The text of a row is a JSON array of the columns.
+type SQLite struct {
+	db    *sql.DB
+	table string
+}
+
+// OpenSQLite opens the table of the database at the path.
+func OpenSQLite(path, table string) (*SQLite, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("SQLite: %w", err)
+	}
+	s := &SQLite{
+		db:    db,
+		table: `"` + strings.ReplaceAll(table, `"`, `""`) + `"`,
+	}
+	// fail fast if the table does not exist
+	if _, err := db.Exec("SELECT rowid FROM " + s.table + " LIMIT 0"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("SQLite: table %s: %w", table, err)
+	}
+	return s, nil
+}
+
+func (s *SQLite) Close() error                      { return s.db.Close() }
+func (*SQLite) Split(text string) ([]string, error) { return SplitJSON(text) }
+
+func (s *SQLite) Records() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		rows, err := s.db.Query("SELECT rowid, * FROM " + s.table + " ORDER BY rowid")
+		if err != nil {
+			yield(nil, fmt.Errorf("Records: %w", err))
+			return
+		}
+		defer rows.Close()
+		cols, err := rows.Columns()
+		if err != nil {
+			yield(nil, fmt.Errorf("Records: %w", err))
+			return
+		}
+
+		var (
+			rowid  int64
+			values = make([]any, len(cols)-1)
+			dest   = make([]any, len(cols))
+		)
+		dest[0] = &rowid
+		for i := range values {
+			dest[i+1] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				yield(nil, fmt.Errorf("Records: %w", err))
+				return
+			}
+			text, err := encodeRow(values)
+			if err != nil {
+				yield(nil, fmt.Errorf("Records: rowid %d %w", rowid, err))
+				return
+			}
+			if !yield(NewRecord(text, rowid, 0), nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("Records: %w", err))
+		}
+	}
+}
+
+func (s *SQLite) Read(offset int64, _ int) ([]byte, error) {
+	rows, err := s.db.Query("SELECT * FROM "+s.table+" WHERE rowid = ?", offset)
+	if err != nil {
+		return nil, fmt.Errorf("Read: rowid %d %w", offset, err)
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("Read: rowid %d %w", offset, err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("Read: rowid %d %w", offset, err)
+		}
+		return nil, fmt.Errorf("Read: rowid %d %w", offset, sql.ErrNoRows)
+	}
+	var (
+		values = make([]any, len(cols))
+		dest   = make([]any, len(cols))
+	)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("Read: rowid %d %w", offset, err)
+	}
+	text, err := encodeRow(values)
+	if err != nil {
+		return nil, fmt.Errorf("Read: rowid %d %w", offset, err)
+	}
+	return []byte(text), nil
+}
+
+// encodeRow encodes the values into a JSON array of strings.
+func encodeRow(values []any) (string, error) {
+	ss := make([]string, len(values))
+	for i, v := range values {
+		ss[i] = sqlValue(v)
+	}
+	b, err := json.Marshal(ss)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func sqlValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}